@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/engine"
+	"github.com/jiaozhu/emorad/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// newEngineCmd 创建 engine 子命令，用于管理反编译引擎
+func newEngineCmd() *cobra.Command {
+	engineCmd := &cobra.Command{
+		Use:   "engine",
+		Short: "Manage decompiler engines",
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install [engine]",
+		Short: "Install an engine JAR from a local file (for offline machines)",
+		Long: `Install a decompiler JAR from a local file into ~/.emorad/<engine>/.
+
+The file is verified before it replaces the cached copy.
+Engines: ` + strings.Join(engine.Names(), ", ") + ` (default: cfr)`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := engine.DefaultEngine
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			from, _ := cmd.Flags().GetString("from")
+			path, err := engine.Install(name, from)
+			if err != nil {
+				return err
+			}
+
+			color.Green(i18n.T("✓ %s 已安装: %s"), name, path)
+			return nil
+		},
+	}
+	installCmd.Flags().String("from", "", "Local JAR file to install")
+	installCmd.MarkFlagRequired("from")
+
+	engineCmd.AddCommand(installCmd)
+	return engineCmd
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/classfile"
+	"github.com/jiaozhu/emorad/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// classSummary 汇总一组 class 文件的元数据
+type classSummary struct {
+	classes  []*classfile.ClassFile
+	versions map[int]int    // Java 版本 -> class 数量
+	packages map[string]int // 包路径 -> class 数量
+	libJars  int            // 依赖库目录中的 JAR 数量
+	invalid  []string       // 无法解析的 class
+}
+
+func newClassSummary() *classSummary {
+	return &classSummary{versions: map[int]int{}, packages: map[string]int{}}
+}
+
+// add 解析一个 class 并计入汇总
+func (s *classSummary) add(name string, r io.Reader) {
+	cf, err := classfile.Parse(r)
+	if err != nil {
+		s.invalid = append(s.invalid, fmt.Sprintf("%s: %v", name, err))
+		return
+	}
+	s.classes = append(s.classes, cf)
+	s.versions[cf.JavaVersion()]++
+	s.packages[cf.PackageName()]++
+}
+
+// newInfoCmd 创建 info 子命令，直接解析 class 文件，不需要 Java 环境
+func newInfoCmd() *cobra.Command {
+	infoCmd := &cobra.Command{
+		Use:   "info <file or directory>",
+		Short: "Show class metadata without a JVM",
+		Long: `Parse class files directly and show class names, superclasses,
+interfaces, annotations and bytecode versions. Java is not required.
+
+For a .class file the full metadata is printed. For JAR/WAR files and
+directories a summary is printed; use --classes to list every class.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inputPath := args[0]
+			stat, err := os.Stat(inputPath)
+			if err != nil {
+				return err
+			}
+
+			if !stat.IsDir() && strings.EqualFold(filepath.Ext(inputPath), ".class") {
+				cf, err := classfile.ParseFile(inputPath)
+				if err != nil {
+					return fmt.Errorf(i18n.T("解析 %s 失败: %v"), inputPath, err)
+				}
+				printClassInfo(cf)
+				return nil
+			}
+
+			summary := newClassSummary()
+			if stat.IsDir() {
+				err = summarizeDirectory(inputPath, summary)
+			} else {
+				err = summarizeArchive(inputPath, summary)
+			}
+			if err != nil {
+				return err
+			}
+
+			listClasses, _ := cmd.Flags().GetBool("classes")
+			printSummary(inputPath, summary, listClasses)
+			return nil
+		},
+	}
+	infoCmd.Flags().Bool("classes", false, "List every class in the summary")
+	return infoCmd
+}
+
+// summarizeArchive 读取 JAR/WAR 中的 class 条目，不解压到磁盘
+func summarizeArchive(archivePath string, summary *classSummary) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf(i18n.T("读取压缩包失败: %v"), err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		name := f.Name
+		switch strings.ToLower(path.Ext(name)) {
+		case ".class":
+			rc, err := f.Open()
+			if err != nil {
+				summary.invalid = append(summary.invalid, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			summary.add(name, rc)
+			rc.Close()
+		case ".jar":
+			if strings.Contains(name, "BOOT-INF/lib") || strings.Contains(name, "WEB-INF/lib") {
+				summary.libJars++
+			}
+		}
+	}
+	return nil
+}
+
+// summarizeDirectory 读取目录下的全部 class 文件
+func summarizeDirectory(dir string, summary *classSummary) error {
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(p), ".class") {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			summary.invalid = append(summary.invalid, fmt.Sprintf("%s: %v", p, err))
+			return nil
+		}
+		defer f.Close()
+		summary.add(p, f)
+		return nil
+	})
+}
+
+// printClassInfo 打印单个 class 的元数据
+func printClassInfo(cf *classfile.ClassFile) {
+	color.Cyan(i18n.T("类名:     %s"), cf.ClassName())
+	fmt.Printf(i18n.T("类型:     %s\n"), cf.Kind())
+	fmt.Printf(i18n.T("访问标志: 0x%04X\n"), cf.AccessFlags)
+	fmt.Printf(i18n.T("字节码:   %d.%d (Java %s)\n"), cf.MajorVersion, cf.MinorVersion, javaVersionLabel(cf.JavaVersion()))
+	if cf.SuperClass != "" {
+		fmt.Printf(i18n.T("父类:     %s\n"), internalToDotted(cf.SuperClass))
+	}
+	for _, iface := range cf.Interfaces {
+		fmt.Printf(i18n.T("接口:     %s\n"), internalToDotted(iface))
+	}
+	for _, annotation := range cf.Annotations {
+		fmt.Printf(i18n.T("注解:     @%s\n"), internalToDotted(annotation))
+	}
+	if cf.SourceFile != "" {
+		fmt.Printf(i18n.T("源文件:   %s\n"), cf.SourceFile)
+	}
+}
+
+// printSummary 打印压缩包或目录的汇总信息
+func printSummary(inputPath string, summary *classSummary, listClasses bool) {
+	color.Cyan(i18n.T("输入路径: %s"), inputPath)
+	fmt.Printf(i18n.T("class 数量: %d\n"), len(summary.classes))
+	fmt.Printf(i18n.T("包数量:     %d\n"), len(summary.packages))
+	if summary.libJars > 0 {
+		fmt.Printf(i18n.T("依赖 JAR:   %d\n"), summary.libJars)
+	}
+
+	if len(summary.versions) > 0 {
+		versions := make([]int, 0, len(summary.versions))
+		for v := range summary.versions {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+		fmt.Println(i18n.T("字节码版本:"))
+		for _, v := range versions {
+			fmt.Printf("   - Java %-4s %d\n", javaVersionLabel(v), summary.versions[v])
+		}
+	}
+
+	if listClasses {
+		sort.Slice(summary.classes, func(i, j int) bool {
+			return summary.classes[i].ThisClass < summary.classes[j].ThisClass
+		})
+		fmt.Println(i18n.T("类列表:"))
+		for _, cf := range summary.classes {
+			line := fmt.Sprintf("   %s %s", cf.Kind(), cf.ClassName())
+			if cf.SuperClass != "" && cf.SuperClass != "java/lang/Object" {
+				line += " extends " + internalToDotted(cf.SuperClass)
+			}
+			if len(cf.Interfaces) > 0 {
+				names := make([]string, len(cf.Interfaces))
+				for i, iface := range cf.Interfaces {
+					names[i] = internalToDotted(iface)
+				}
+				line += " implements " + strings.Join(names, ", ")
+			}
+			fmt.Printf("%s [Java %s]\n", line, javaVersionLabel(cf.JavaVersion()))
+		}
+	}
+
+	if len(summary.invalid) > 0 {
+		color.Yellow(i18n.T("[WARN] %d 个 class 无法解析:"), len(summary.invalid))
+		for _, msg := range summary.invalid {
+			color.Yellow("   %s", msg)
+		}
+	}
+}
+
+// javaVersionLabel 返回 Java 版本的显示名称
+func javaVersionLabel(version int) string {
+	switch version {
+	case 0:
+		return i18n.T("未知")
+	case 1:
+		return "1.4-"
+	default:
+		return fmt.Sprint(version)
+	}
+}
+
+// internalToDotted 将 JVM 内部类名转换为全限定名
+func internalToDotted(name string) string {
+	return strings.ReplaceAll(name, "/", ".")
+}
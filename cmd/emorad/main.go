@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/cfr"
+	"github.com/jiaozhu/emorad/internal/config"
 	"github.com/jiaozhu/emorad/internal/decompile"
+	"github.com/jiaozhu/emorad/internal/engine"
+	"github.com/jiaozhu/emorad/internal/i18n"
 	"github.com/jiaozhu/emorad/internal/processor"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +29,9 @@ var (
 
 var rootCmd *cobra.Command
 
+// exitCode 根命令的退出码，出错时设置，见 decompile.Exit* 常量
+var exitCode = decompile.ExitOK
+
 func isTomcatDeployDir(path string) bool {
 	classesPath := filepath.Join(path, "WEB-INF", "classes")
 	if stat, err := os.Stat(classesPath); err == nil && stat.IsDir() {
@@ -34,25 +46,151 @@ func isTomcatDeployDir(path string) bool {
 	return false
 }
 
-func parsePackagePrefixes(input string) []string {
+// parseClassPatterns 解析逗号分隔的 class 过滤规则
+// 包名写法转换为以 / 分隔的路径，见 normalizeClassPattern；
+// re: 开头的正则表达式保持原样，! 前缀表示取反
+func parseClassPatterns(input string) []string {
 	if input == "" {
 		return nil
 	}
-	parts := strings.Split(input, ",")
-	result := make([]string, 0, len(parts))
-	for _, p := range parts {
+	return parseClassPatternList(splitClassPatterns(input))
+}
+
+// parseClassPatternList 解析一组 class 过滤规则，每项是一条完整的规则，不按逗号拆分
+// 用于配置文件中的 include、exclude 列表，其中的 re: 规则可以直接包含逗号
+func parseClassPatternList(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, p := range items {
 		p = strings.TrimSpace(p)
-		if p != "" {
-			p = strings.ReplaceAll(p, ".", "/")
-			if !strings.HasSuffix(p, "/") {
-				p += "/"
-			}
-			result = append(result, p)
+		negate := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		if p == "" {
+			continue
+		}
+		if !strings.HasPrefix(p, "re:") {
+			p = normalizeClassPattern(p)
 		}
+		if negate {
+			p = "!" + p
+		}
+		result = append(result, p)
 	}
 	return result
 }
 
+// classPatterns 返回命令行参数 name 中的 class 过滤规则，未指定时使用配置文件中的列表
+func classPatterns(cmd *cobra.Command, name string, fileValues []string) []string {
+	if cmd.Flags().Changed(name) {
+		value, _ := cmd.Flags().GetString(name)
+		return parseClassPatterns(value)
+	}
+	return parseClassPatternList(fileValues)
+}
+
+// normalizeClassPattern 将包名写法转换为过滤使用的路径规则
+// 不含 / 时只转换包名部分的 .，末尾的 .class 保持不变：com.acme.*Dto -> com/acme/*Dto，
+// com.acme.Foo.class -> com/acme/Foo.class；不含包名的类名通配符匹配任意包，如 *Proto.class -> **/*Proto.class。
+// 不含通配符和 .class 的规则按包前缀匹配，如 com.acme -> com/acme/
+func normalizeClassPattern(p string) string {
+	wildcard := strings.ContainsAny(p, "*?")
+	name, isClass := strings.CutSuffix(p, ".class")
+	if !strings.Contains(p, "/") {
+		name = strings.ReplaceAll(name, ".", "/")
+		if wildcard && !strings.Contains(name, "/") {
+			name = "**/" + name
+		}
+	}
+	switch {
+	case isClass:
+		return name + ".class"
+	case !wildcard && !strings.HasSuffix(name, "/"):
+		return name + "/"
+	}
+	return name
+}
+
+// splitClassPatterns 按逗号拆分规则，re: 规则中位于 {}、[]、() 内或以 \ 转义的逗号属于正则本身，
+// 如 re:.*Dto{1,3}
+func splitClassPatterns(input string) []string {
+	var parts []string
+	start, depth := 0, 0
+	regex := strings.HasPrefix(strings.TrimLeft(input, " !"), "re:")
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case regex && c == '\\':
+			i++
+		case regex && strings.IndexByte("{[(", c) >= 0:
+			depth++
+		case regex && strings.IndexByte("}])", c) >= 0 && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, input[start:i])
+			start = i + 1
+			regex = strings.HasPrefix(strings.TrimLeft(input[start:], " !"), "re:")
+		}
+	}
+	return append(parts, input[start:])
+}
+
+// parseByteSize 解析带单位的大小，如 512MB、8G，不带单位时按字节计算
+func parseByteSize(input string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	s = strings.TrimSuffix(s, "B")
+	units := map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		if m, ok := units[s[n-1:]]; ok {
+			multiplier = m
+			s = s[:n-1]
+		}
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf(i18n.T("无效的大小: %q"), input)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// parsePercent 解析百分比，如 5%、0.5%，不带 % 时同样按百分比计算，返回 0-1 之间的比例
+func parsePercent(input string) (float64, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(input), "%")
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 || value > 100 {
+		return 0, fmt.Errorf(i18n.T("无效的百分比: %q"), input)
+	}
+	return value / 100, nil
+}
+
+// loadConfig 读取 --config 指定的配置文件，未指定时自动查找
+func loadConfig(cmd *cobra.Command, inputPath string) (*config.Config, error) {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		return config.Load(path)
+	}
+	return config.Discover(inputPath)
+}
+
+// applySettings 将配置文件中的选项作为命令行参数的默认值，命令行中显式指定的参数优先
+func applySettings(cmd *cobra.Command, settings config.Settings) error {
+	for name, value := range settings.FlagValues() {
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf(i18n.T("配置项 %s 无效: %v"), name, err)
+		}
+	}
+	return nil
+}
+
+// cfrOptions 合并配置文件和命令行中的 CFR 选项，命令行优先
+func cfrOptions(fileOptions map[string]string, cliValues []string) (map[string]string, error) {
+	var values []string
+	for key, value := range fileOptions {
+		values = append(values, key+"="+value)
+	}
+	return cfr.ParseOptions(append(values, cliValues...))
+}
+
 func init() {
 	rootCmd = &cobra.Command{
 		Use:   "emorad [file or directory]",
@@ -62,14 +200,38 @@ func init() {
 Automatically filters framework code and generates HTML/JSON reports.
 Without arguments, decompiles the current directory.`,
 		Version: Version,
+		Args:    cobra.MaximumNArgs(1),
+		// 未指定 --lang 时根据 LC_ALL、LC_MESSAGES、LANG 选择消息语言
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			lang, _ := cmd.Flags().GetString("lang")
+			if err := i18n.SetLang(lang); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			var inputPath string
 			var err error
 
+			// ndjson 模式下 stdout 只输出事件，提示信息不带颜色地写入 stderr
+			logFormat, _ := cmd.Flags().GetString("log-format")
+			switch logFormat {
+			case "text":
+			case "ndjson":
+				color.NoColor = true
+				color.Output = os.Stderr
+			default:
+				color.Red(i18n.T("Error: 无效的日志格式 %q，可选 text、ndjson"), logFormat)
+				exitCode = decompile.ExitInput
+				return
+			}
+
 			if len(args) == 0 {
 				inputPath, err = os.Getwd()
 				if err != nil {
 					color.Red("Error: cannot get current directory: %v", err)
+					exitCode = decompile.ExitInput
 					return
 				}
 
@@ -77,6 +239,7 @@ Without arguments, decompiles the current directory.`,
 					color.Red("Error: current directory is not a valid Tomcat deployment")
 					color.Yellow("Hint: directory should contain WEB-INF/classes or WEB-INF/lib")
 					color.Yellow("Hint: or specify a JAR/WAR file or directory as argument")
+					exitCode = decompile.ExitInput
 					return
 				}
 			} else {
@@ -86,6 +249,7 @@ Without arguments, decompiles the current directory.`,
 			absInputPath, err := filepath.Abs(inputPath)
 			if err != nil {
 				color.Red("Error: cannot get absolute path: %v", err)
+				exitCode = decompile.ExitInput
 				return
 			}
 
@@ -98,10 +262,37 @@ Without arguments, decompiles the current directory.`,
 				}
 			}
 
+			cfg, err := loadConfig(cmd, absInputPath)
+			if err != nil {
+				color.Red("Error: %v", err)
+				exitCode = decompile.ExitInput
+				return
+			}
+			profile, _ := cmd.Flags().GetString("profile")
+			var settings config.Settings
+			if cfg != nil {
+				color.Cyan(i18n.T("[CONFIG] 使用配置文件: %s"), cfg.Path())
+				if settings, err = cfg.Resolve(profile); err != nil {
+					color.Red("Error: %v", err)
+					exitCode = decompile.ExitInput
+					return
+				}
+				if profile != "" {
+					color.Cyan(i18n.T("[CONFIG] 使用配置方案: %s"), profile)
+				}
+				if err := applySettings(cmd, settings); err != nil {
+					color.Red("Error: %v", err)
+					exitCode = decompile.ExitInput
+					return
+				}
+			} else if profile != "" {
+				color.Red(i18n.T("Error: 未找到配置文件 %s，无法使用方案 %q"), config.FileName, profile)
+				exitCode = decompile.ExitInput
+				return
+			}
+
 			workers, _ := cmd.Flags().GetInt("workers")
 
-			includeStr, _ := cmd.Flags().GetString("include")
-			excludeStr, _ := cmd.Flags().GetString("exclude")
 			jarIncludeStr, _ := cmd.Flags().GetString("jar-include")
 			skipLibs, _ := cmd.Flags().GetBool("skip-libs")
 			noDefaultExclude, _ := cmd.Flags().GetBool("no-default-exclude")
@@ -111,17 +302,67 @@ Without arguments, decompiles the current directory.`,
 			filterConfig.CopyResources, _ = cmd.Flags().GetBool("copy-resources")
 			filterConfig.CopyLibJars, _ = cmd.Flags().GetBool("copy-libs")
 			filterConfig.GenerateIDEA, _ = cmd.Flags().GetBool("idea-project")
+			filterConfig.GenerateMaven, _ = cmd.Flags().GetBool("maven-project")
+			filterConfig.GenerateGradle, _ = cmd.Flags().GetString("gradle-project")
+			filterConfig.GenerateEclipse, _ = cmd.Flags().GetBool("eclipse-project")
+			filterConfig.GenerateVSCode, _ = cmd.Flags().GetBool("vscode-project")
+			filterConfig.Layout, _ = cmd.Flags().GetString("layout")
+			filterConfig.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+			filterConfig.UseDaemon, _ = cmd.Flags().GetBool("daemon")
+			filterConfig.Engine, _ = cmd.Flags().GetString("engine")
+			filterConfig.FallbackEngine, _ = cmd.Flags().GetString("fallback-engine")
+			filterConfig.ClassTimeout, _ = cmd.Flags().GetDuration("class-timeout")
+			filterConfig.CFRJar, _ = cmd.Flags().GetString("cfr-jar")
+			filterConfig.Mirrors, _ = cmd.Flags().GetStringSlice("mirror")
 
-			if includes := parsePackagePrefixes(includeStr); len(includes) > 0 {
-				filterConfig.Includes = includes
+			maxUnpacked, _ := cmd.Flags().GetString("max-unpacked-size")
+			if filterConfig.Limits.MaxTotalBytes, err = parseByteSize(maxUnpacked); err != nil {
+				color.Red("Error: %v", err)
+				exitCode = decompile.ExitInput
+				return
 			}
+			filterConfig.Limits.MaxEntries, _ = cmd.Flags().GetInt("max-entries")
+			filterConfig.Limits.MaxRatio, _ = cmd.Flags().GetFloat64("max-ratio")
+			filterConfig.Limits.MaxDepth, _ = cmd.Flags().GetInt("max-depth")
 
-			if excludes := parsePackagePrefixes(excludeStr); len(excludes) > 0 {
-				filterConfig.Excludes = append(filterConfig.Excludes, excludes...)
+			filterConfig.CacheDir, _ = cmd.Flags().GetString("cache-dir")
+			if useCache, _ := cmd.Flags().GetBool("cache"); useCache && filterConfig.CacheDir == "" {
+				if filterConfig.CacheDir, err = cache.DefaultDir(); err != nil {
+					color.Red("Error: %v", err)
+					exitCode = decompile.ExitInput
+					return
+				}
+			}
+
+			filterConfig.Resume, _ = cmd.Flags().GetBool("resume")
+			var output decompile.Output
+			if logFormat == "ndjson" {
+				output.Events = os.Stdout
+			}
+
+			errorRate, _ := cmd.Flags().GetString("fail-on-error-rate")
+			if filterConfig.MaxErrorRate, err = parsePercent(errorRate); err != nil {
+				color.Red("Error: %v", err)
+				exitCode = decompile.ExitInput
+				return
+			}
+
+			cfrOptValues, _ := cmd.Flags().GetStringArray("cfr-opt")
+			if filterConfig.CFROptions, err = cfrOptions(settings.CFR, cfrOptValues); err != nil {
+				color.Red("Error: %v", err)
+				exitCode = decompile.ExitInput
+				return
 			}
 
-			if noDefaultExclude {
-				filterConfig.Excludes = parsePackagePrefixes(excludeStr)
+			filterConfig.Includes = classPatterns(cmd, "include", settings.Include)
+
+			// 指定包含规则时默认排除列表不再生效，只在包含范围内应用 --exclude，
+			// 以免 -i org.apache.mycompany 这类业务包被框架排除规则覆盖
+			excludes := classPatterns(cmd, "exclude", settings.Exclude)
+			if noDefaultExclude || len(filterConfig.Includes) > 0 {
+				filterConfig.Excludes = excludes
+			} else {
+				filterConfig.Excludes = append(filterConfig.Excludes, excludes...)
 			}
 
 			if jarIncludeStr != "" {
@@ -134,8 +375,16 @@ Without arguments, decompiles the current directory.`,
 				}
 			}
 
-			if err := decompile.Run(absInputPath, outputDir, workers, filterConfig); err != nil {
+			ctx := cmd.Context()
+			if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			if _, err := decompile.Run(ctx, absInputPath, outputDir, workers, filterConfig, output); err != nil {
 				color.Red("Decompile failed: %v", err)
+				exitCode = decompile.ExitCode(err)
 				return
 			}
 		},
@@ -143,19 +392,62 @@ Without arguments, decompiles the current directory.`,
 
 	rootCmd.Flags().StringP("output", "o", "", "Output directory (default: ./src)")
 	rootCmd.Flags().IntP("workers", "w", runtime.NumCPU(), "Number of concurrent workers")
-	rootCmd.Flags().StringP("include", "i", "", "Only process matching package prefixes, comma-separated")
-	rootCmd.Flags().StringP("exclude", "e", "", "Exclude matching package prefixes, comma-separated")
+	rootCmd.Flags().StringP("include", "i", "", "Only process matching classes: package prefixes, Ant globs (com/acme/**/dto/*), re:<regex>, !negation; comma-separated")
+	rootCmd.Flags().StringP("exclude", "e", "", "Exclude matching classes within the includes; same syntax as --include")
 	rootCmd.Flags().Bool("skip-libs", true, "Skip JAR files in lib directory")
 	rootCmd.Flags().Bool("no-default-exclude", false, "Disable default framework exclusion list")
 	rootCmd.Flags().StringP("jar-include", "j", "", "Only process lib JARs containing specified keywords")
 	rootCmd.Flags().BoolP("copy-resources", "r", false, "Copy resource files to output/resources")
 	rootCmd.Flags().Bool("copy-libs", false, "Copy dependency JARs to output/libs")
 	rootCmd.Flags().Bool("idea-project", false, "Generate IDEA project structure with .iml file")
+	rootCmd.Flags().Bool("maven-project", false, "Generate pom.xml with dependencies resolved from lib JARs (implies --copy-libs)")
+	rootCmd.Flags().String("gradle-project", "", "Generate Gradle build files with src/main/java layout: groovy or kotlin (implies --copy-libs)")
+	rootCmd.Flags().Lookup("gradle-project").NoOptDefVal = processor.GradleGroovy
+	rootCmd.Flags().Bool("eclipse-project", false, "Generate Eclipse .project and .classpath (implies --copy-libs)")
+	rootCmd.Flags().Bool("vscode-project", false, "Generate .vscode/settings.json for the VS Code Java extension (implies --copy-libs)")
+	rootCmd.Flags().String("layout", processor.LayoutFlat, "Output layout: flat, or maven (src/main/java, src/main/resources, src/main/webapp)")
+	rootCmd.Flags().Int("batch-size", 0, "Decompile up to N classes per CFR process (0: one process per class)")
+	rootCmd.Flags().Bool("daemon", false, "Keep one long-lived CFR JVM per worker (requires JDK 11+)")
+	rootCmd.Flags().String("engine", engine.DefaultEngine, "Decompiler engine: "+strings.Join(engine.Names(), ", "))
+	rootCmd.Flags().String("fallback-engine", "", "Retry failed classes with this engine and keep the better result")
+	rootCmd.Flags().Duration("class-timeout", 0, "Per-class decompile timeout, e.g. 30s (0: no limit)")
+	rootCmd.Flags().Duration("timeout", 0, "Overall timeout for the whole run, e.g. 30m (0: no limit)")
+	rootCmd.Flags().String("cfr-jar", "", "Use this CFR JAR instead of downloading (env: EMORAD_CFR_JAR)")
+	rootCmd.Flags().StringSlice("mirror", nil, "Decompiler download mirror: Maven repository URL or URL template with {file} (env: EMORAD_MIRRORS)")
+
+	defaultLimits := processor.DefaultArchiveLimits()
+	rootCmd.Flags().String("max-unpacked-size", "8GB", "Max bytes unpacked from one input archive including nested JARs (0: no limit)")
+	rootCmd.Flags().Int("max-entries", defaultLimits.MaxEntries, "Max entries per archive (0: no limit)")
+	rootCmd.Flags().Float64("max-ratio", defaultLimits.MaxRatio, "Max compression ratio per entry larger than 1MB (0: no limit)")
+	rootCmd.Flags().Int("max-depth", defaultLimits.MaxDepth, "Max nesting depth of JARs inside archives (0: no limit)")
+	rootCmd.Flags().Bool("cache", false, "Reuse results for unchanged classes from ~/.emorad/cache")
+	rootCmd.Flags().String("cache-dir", "", "Cache directory for decompiled classes (implies --cache)")
+	rootCmd.Flags().Bool("resume", false, "Skip work completed by an interrupted run with the same input and output")
+	rootCmd.Flags().String("fail-on-error-rate", "0%", "Exit with code 4 when more than this share of classes fail, e.g. 5%")
+	rootCmd.Flags().String("log-format", "text", "Console output format: text, or ndjson for one JSON event per line on stdout")
+	rootCmd.Flags().StringArray("cfr-opt", nil, "Pass an option to CFR as key=value, repeatable (e.g. --cfr-opt renamedupmembers=true)")
+	rootCmd.Flags().String("config", "", "Config file (default: "+config.FileName+" in the input directory or ~/.emorad/)")
+	rootCmd.Flags().String("profile", "", "Use a named profile from the config file")
+
+	rootCmd.PersistentFlags().String("lang", "", "Message language: zh or en (default: detected from LANG)")
+
+	rootCmd.AddCommand(newEngineCmd())
+	rootCmd.AddCommand(newInfoCmd())
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// Ctrl+C 时取消所有反编译进程并生成部分报告
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// 第一次中断后恢复默认行为，再次按 Ctrl+C 可强制退出
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(decompile.ExitCode(err))
 	}
+	os.Exit(exitCode)
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestClassPatterns(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("include", "", "")
+
+	// 配置文件中的每一项是一条完整的规则，正则中的逗号不拆分
+	fileValues := []string{"re:.*Dto{1,3}\\.class$", "!re:(Foo|Bar),Baz", "com.acme.*Dto", " "}
+	want := []string{"re:.*Dto{1,3}\\.class$", "!re:(Foo|Bar),Baz", "com/acme/*Dto"}
+	if got := classPatterns(cmd, "include", fileValues); !reflect.DeepEqual(got, want) {
+		t.Errorf("classPatterns() = %q, want %q", got, want)
+	}
+
+	cmd.Flags().Set("include", "com.other")
+	if got := classPatterns(cmd, "include", fileValues); !reflect.DeepEqual(got, []string{"com/other/"}) {
+		t.Errorf("classPatterns() with flag = %q, want command line value", got)
+	}
+}
+
+func TestParseClassPatterns(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"包名和通配符", "com.acme, com/acme/**/dto/*", []string{"com/acme/", "com/acme/**/dto/*"}},
+		{"取反", "com.acme,!com.acme.internal", []string{"com/acme/", "!com/acme/internal/"}},
+		{"正则中的量词", "re:.*Dto{1,3}\\.class$,com.acme", []string{"re:.*Dto{1,3}\\.class$", "com/acme/"}},
+		{"正则中的分组和字符类", "!re:.*(Foo|Bar,Baz)[,;]x,re:a\\,b", []string{"!re:.*(Foo|Bar,Baz)[,;]x", "re:a\\,b"}},
+		{"空项", "com.acme,,", []string{"com/acme/"}},
+		{"点分通配符", "com.acme.*Dto", []string{"com/acme/*Dto"}},
+		{"类名通配符匹配任意包", "!*Proto.class", []string{"!**/*Proto.class"}},
+		{"带 .class 的类名", "com.acme.Foo.class,com/acme/Bar.class", []string{"com/acme/Foo.class", "com/acme/Bar.class"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseClassPatterns(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseClassPatterns(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// formatVersion 缓存格式版本，格式变化时旧缓存自动失效
+const formatVersion = "1"
+
+// Entry 一个源文件的反编译结果
+type Entry struct {
+	SourcePath string `json:"sourcePath"` // 相对于输出目录的 .java 路径，以 / 分隔
+	Engine     string `json:"engine"`     // 生成源码的引擎
+	Source     string `json:"source"`     // 源码内容
+}
+
+// Cache 以 class 文件内容的 SHA-256 为键保存反编译结果
+// 键同时包含引擎和选项，更换引擎或 CFR 选项后不会命中旧结果
+type Cache struct {
+	dir  string
+	salt string // 引擎、备用引擎和选项等影响输出的配置
+}
+
+// DefaultDir 返回默认缓存目录 ~/.emorad/cache
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("无法获取用户主目录: %v"), err)
+	}
+	return filepath.Join(homeDir, ".emorad", "cache"), nil
+}
+
+// New 打开缓存目录，不存在时创建
+// salt 描述影响反编译输出的配置，如引擎名称和选项
+func New(dir, salt string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf(i18n.T("创建缓存目录失败: %v"), err)
+	}
+	return &Cache{dir: dir, salt: salt}, nil
+}
+
+// Dir 返回缓存目录
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Key 计算一组 class 文件的缓存键
+// 同一源文件的顶层类和内部类共同决定输出，因此全部计入；顺序不影响结果
+func (c *Cache) Key(classFiles []string) (string, error) {
+	files := append([]string{}, classFiles...)
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Base(files[i]) < filepath.Base(files[j])
+	})
+
+	h := sha256.New()
+	fmt.Fprintf(h, "emorad-cache-%s\n%s\n", formatVersion, c.salt)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n%d\n", filepath.Base(file), info.Size())
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// path 返回缓存条目的文件路径，按键的前两位分目录存放
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get 读取缓存条目，不存在或已损坏时返回 false
+func (c *Cache) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.SourcePath == "" {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put 保存缓存条目，先写入临时文件再重命名，并发写入同一键时不会产生残缺文件
+func (c *Cache) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	dest := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后为空操作
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, dest)
+}
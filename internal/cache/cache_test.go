@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	classDir := t.TempDir()
+	outer := filepath.Join(classDir, "Foo.class")
+	inner := filepath.Join(classDir, "Foo$1.class")
+	for path, content := range map[string]string{outer: "outer", inner: "inner"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := New(dir, "engine=cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := c.Key([]string{outer, inner})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 顺序不影响键
+	if other, _ := c.Key([]string{inner, outer}); other != key {
+		t.Errorf("Key() 与顺序有关: %s != %s", other, key)
+	}
+	// 引擎或选项不同时键不同
+	other, _ := New(dir, "engine=procyon")
+	if otherKey, _ := other.Key([]string{outer, inner}); otherKey == key {
+		t.Error("不同引擎的缓存键不应相同")
+	}
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() 不应命中空缓存")
+	}
+	entry := &Entry{SourcePath: "com/acme/Foo.java", Engine: "cfr", Source: "class Foo {}"}
+	if err := c.Put(key, entry); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := c.Get(key)
+	if !ok || *got != *entry {
+		t.Errorf("Get() = %+v, %v, want %+v", got, ok, entry)
+	}
+
+	// 内容变化后不再命中
+	if err := os.WriteFile(inner, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changedKey, _ := c.Key([]string{outer, inner}); changedKey == key {
+		t.Error("内容变化后缓存键不应相同")
+	}
+}
@@ -0,0 +1,349 @@
+package cfr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// daemonSource 常驻进程的 Java 源码，由 java 源码启动器直接运行（需要 JDK 11+）
+//
+//go:embed daemon/EmoradDaemon.java
+var daemonSource []byte
+
+const (
+	daemonStartTimeout = 60 * time.Second // 启动并编译常驻进程的最长等待时间
+	daemonStopTimeout  = 5 * time.Second  // 关闭常驻进程的最长等待时间
+)
+
+// errDaemonUnavailable 表示常驻进程无法处理该请求，调用方应回退到独立进程
+var errDaemonUnavailable error = i18n.Error("常驻进程不可用")
+
+// daemonResponse 常驻进程返回的结构化状态
+type daemonResponse struct {
+	ID      int64  `json:"id"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Elapsed int64  `json:"elapsed"`
+}
+
+// daemonProcess 单个常驻 JVM 进程
+type daemonProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID int64
+}
+
+// daemonPool 常驻进程池，每个槽位同一时间只服务一个 worker
+// 槽位为 nil 表示进程已退出，下次取用时会尝试重新启动
+type daemonPool struct {
+	manager *Manager
+	slots   chan *daemonProcess
+	size    int
+}
+
+// StartDaemon 启动 size 个常驻 CFR 进程，之后 Decompile 将通过常驻进程完成
+// 仅在使用 CFR JAR 时可用；启动失败时返回错误，Manager 继续使用独立进程模式
+func (m *Manager) StartDaemon(size int) error {
+	if !m.useJar {
+		return fmt.Errorf(m.console.T("系统 CFR 命令不支持常驻进程模式"))
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	srcPath := filepath.Join(filepath.Dir(m.cfrPath), "daemon", "EmoradDaemon.java")
+	if err := writeDaemonSource(srcPath); err != nil {
+		return err
+	}
+	m.daemonSrc = srcPath
+
+	// 先启动一个进程确认环境支持，再启动其余进程
+	first, err := m.startDaemonProcess()
+	if err != nil {
+		return err
+	}
+
+	pool := &daemonPool{
+		manager: m,
+		slots:   make(chan *daemonProcess, size),
+		size:    size,
+	}
+	pool.slots <- first
+
+	type started struct {
+		proc *daemonProcess
+		err  error
+	}
+	results := make(chan started, size-1)
+	for i := 1; i < size; i++ {
+		go func() {
+			proc, err := m.startDaemonProcess()
+			results <- started{proc, err}
+		}()
+	}
+	for i := 1; i < size; i++ {
+		r := <-results
+		if r.err != nil {
+			m.console.Warn(m.console.T("[WARN] 常驻进程启动失败，将在使用时重试: %v"), r.err)
+		}
+		pool.slots <- r.proc
+	}
+
+	m.pool = pool
+	m.console.Green(m.console.T("✓ 已启动 %d 个常驻 CFR 进程"), size)
+	return nil
+}
+
+// Close 关闭所有常驻进程，未启动常驻模式时不做任何操作
+func (m *Manager) Close() error {
+	if m.pool == nil {
+		return nil
+	}
+	pool := m.pool
+	m.pool = nil
+
+	// 取回全部槽位，等待正在处理的请求结束
+	for i := 0; i < pool.size; i++ {
+		if proc := <-pool.slots; proc != nil {
+			proc.stop()
+		}
+	}
+	return nil
+}
+
+// writeDaemonSource 写出常驻进程源码，内容未变化时跳过
+// 多个运行可能同时启动常驻进程，先写入同目录的临时文件再重命名，其他进程不会读到写了一半的源码
+func writeDaemonSource(path string) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, daemonSource) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(i18n.T("创建常驻进程目录失败: %v"), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf(i18n.T("写入常驻进程源码失败: %v"), err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后为空操作
+
+	_, err = tmp.Write(daemonSource)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("写入常驻进程源码失败: %v"), err)
+	}
+	return nil
+}
+
+// startDaemonProcess 启动一个常驻 JVM 并等待其就绪
+func (m *Manager) startDaemonProcess() (*daemonProcess, error) {
+	cmd := exec.Command(m.javaPath, "-Dfile.encoding=UTF-8", "-cp", m.cfrPath, m.daemonSrc)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &tailBuffer{limit: 4096}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf(m.console.T("启动常驻进程失败: %v"), err)
+	}
+
+	proc := &daemonProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		resp, err := proc.readResponse()
+		if err == nil && resp.Status != "ready" {
+			err = fmt.Errorf(m.console.T("意外的握手响应: %s"), resp.Status)
+		}
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+	case <-time.After(daemonStartTimeout):
+		err = fmt.Errorf(m.console.T("等待就绪超时"))
+	}
+	if err != nil {
+		proc.kill()
+		return nil, fmt.Errorf(m.console.T("常驻进程未就绪: %v %s"), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return proc, nil
+}
+
+// decompile 通过常驻进程反编译，返回 errDaemonUnavailable 时调用方应回退
+// sourcePath 不为空时常驻进程确认生成了该源文件，否则返回错误
+// ctx 取消时强制结束正在处理的进程，该槽位会在下次取用时重启
+func (p *daemonPool) decompile(ctx context.Context, inputPath, outputDir, sourcePath string, options map[string]string) error {
+	if strings.ContainsAny(inputPath+outputDir+sourcePath, "\t\r\n") {
+		return errDaemonUnavailable
+	}
+	for key, value := range options {
+		if strings.ContainsAny(key+value, "\t\r\n") {
+			return errDaemonUnavailable
+		}
+	}
+
+	var proc *daemonProcess
+	select {
+	case proc = <-p.slots:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if proc == nil {
+		var err error
+		if proc, err = p.manager.startDaemonProcess(); err != nil {
+			p.slots <- nil
+			return errDaemonUnavailable
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		proc.cmd.Process.Kill()
+	})
+	resp, err := proc.request(inputPath, outputDir, sourcePath, options)
+	if !stop() {
+		// 进程已因超时或取消被结束
+		proc.kill()
+		p.slots <- nil
+		return ctx.Err()
+	}
+	if err != nil {
+		// 进程已崩溃或协议错误，丢弃该进程，下次取用时重启
+		p.manager.console.Warn(p.manager.console.T("[WARN] 常驻进程异常，正在重启: %v"), err)
+		proc.kill()
+		p.slots <- nil
+		return errDaemonUnavailable
+	}
+	p.slots <- proc
+
+	if resp.Status != "ok" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// request 发送一次反编译请求并读取响应，选项按名称排序，与独立进程的参数顺序一致
+func (d *daemonProcess) request(inputPath, outputDir, sourcePath string, options map[string]string) (*daemonResponse, error) {
+	d.nextID++
+	fields := []string{fmt.Sprint(d.nextID), "decompile", inputPath, outputDir, filepath.ToSlash(sourcePath)}
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := options[key]; value == "" {
+			fields = append(fields, key)
+		} else {
+			fields = append(fields, key+"="+value)
+		}
+	}
+
+	if _, err := io.WriteString(d.stdin, strings.Join(fields, "\t")+"\n"); err != nil {
+		return nil, err
+	}
+
+	resp, err := d.readResponse()
+	if err != nil {
+		return nil, err
+	}
+	if resp.ID != d.nextID {
+		return nil, fmt.Errorf(i18n.T("响应编号不匹配: %d != %d"), resp.ID, d.nextID)
+	}
+	return resp, nil
+}
+
+// readResponse 读取一行 JSON 响应
+func (d *daemonProcess) readResponse() (*daemonResponse, error) {
+	line, err := d.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var resp daemonResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf(i18n.T("无法解析响应: %v"), err)
+	}
+	return &resp, nil
+}
+
+// stop 请求常驻进程退出，超时后强制结束
+func (d *daemonProcess) stop() {
+	io.WriteString(d.stdin, "0\tquit\n")
+	d.stdin.Close()
+
+	done := make(chan struct{})
+	go func() {
+		d.cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(daemonStopTimeout):
+		d.cmd.Process.Kill()
+		<-done
+	}
+}
+
+// tailBuffer 只保留最后 limit 字节的输出，用于记录常驻进程的 stderr
+type tailBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+// kill 强制结束常驻进程
+func (d *daemonProcess) kill() {
+	d.stdin.Close()
+	d.cmd.Process.Kill()
+	d.cmd.Wait()
+}
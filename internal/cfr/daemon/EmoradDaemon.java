@@ -0,0 +1,112 @@
+import java.io.BufferedReader;
+import java.io.FileDescriptor;
+import java.io.FileOutputStream;
+import java.io.InputStreamReader;
+import java.io.PrintStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Paths;
+import java.util.Collections;
+import java.util.HashMap;
+import java.util.Map;
+
+import org.benf.cfr.reader.api.CfrDriver;
+
+/**
+ * emorad 常驻反编译进程。
+ *
+ * 请求（stdin，每行一个，字段以 TAB 分隔）:
+ *   <id> decompile <input> <outputdir> <source> [key=value ...]
+ *   <id> quit
+ *
+ * <source> 为预期生成的源文件（相对于 outputdir，以 / 分隔），为空时不检查。
+ * CFR 对大多数失败只在输出中以注释说明而不抛出异常，因此没有生成该文件时返回 error。
+ *
+ * 响应（stdout，每行一个 JSON）:
+ *   {"id":1,"status":"ok","elapsed":12}
+ *   {"id":1,"status":"error","error":"...","elapsed":12}
+ */
+public class EmoradDaemon {
+    public static void main(String[] args) throws Exception {
+        PrintStream out = new PrintStream(new FileOutputStream(FileDescriptor.out), true, "UTF-8");
+        // CFR 的进度信息写入 stdout，重定向到 stderr 以免破坏协议
+        System.setOut(System.err);
+
+        BufferedReader in = new BufferedReader(new InputStreamReader(System.in, StandardCharsets.UTF_8));
+        out.println("{\"id\":0,\"status\":\"ready\"}");
+
+        String line;
+        while ((line = in.readLine()) != null) {
+            String[] fields = line.split("\t", -1);
+            if (fields.length < 2) {
+                continue;
+            }
+            String id = fields[0];
+            if (fields[1].equals("quit")) {
+                break;
+            }
+
+            long start = System.currentTimeMillis();
+            if (!fields[1].equals("decompile") || fields.length < 5) {
+                reply(out, id, "error", "invalid request", start);
+                continue;
+            }
+
+            try {
+                Map<String, String> options = new HashMap<>();
+                options.put("caseinsensitivefs", "true");
+                for (int i = 5; i < fields.length; i++) {
+                    int eq = fields[i].indexOf('=');
+                    if (eq > 0) {
+                        options.put(fields[i].substring(0, eq), fields[i].substring(eq + 1));
+                    } else if (!fields[i].isEmpty()) {
+                        options.put(fields[i], "true");
+                    }
+                }
+                options.put("outputdir", fields[3]);
+
+                CfrDriver driver = new CfrDriver.Builder().withOptions(options).build();
+                driver.analyse(Collections.singletonList(fields[2]));
+                if (!fields[4].isEmpty() && !Files.isRegularFile(Paths.get(fields[3], fields[4]))) {
+                    reply(out, id, "error", "no output: " + fields[4], start);
+                } else {
+                    reply(out, id, "ok", null, start);
+                }
+            } catch (Throwable t) {
+                reply(out, id, "error", t.toString(), start);
+            }
+        }
+    }
+
+    private static void reply(PrintStream out, String id, String status, String error, long start) {
+        StringBuilder sb = new StringBuilder();
+        sb.append("{\"id\":").append(id);
+        sb.append(",\"status\":\"").append(status).append('"');
+        if (error != null) {
+            sb.append(",\"error\":\"").append(escape(error)).append('"');
+        }
+        sb.append(",\"elapsed\":").append(System.currentTimeMillis() - start);
+        sb.append('}');
+        out.println(sb);
+    }
+
+    private static String escape(String s) {
+        StringBuilder sb = new StringBuilder();
+        for (char c : s.toCharArray()) {
+            switch (c) {
+                case '"': sb.append("\\\""); break;
+                case '\\': sb.append("\\\\"); break;
+                case '\n': sb.append("\\n"); break;
+                case '\r': sb.append("\\r"); break;
+                case '\t': sb.append("\\t"); break;
+                default:
+                    if (c < 0x20) {
+                        sb.append(String.format("\\u%04x", (int) c));
+                    } else {
+                        sb.append(c);
+                    }
+            }
+        }
+        return sb.toString();
+    }
+}
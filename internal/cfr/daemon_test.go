@@ -0,0 +1,202 @@
+package cfr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/console"
+)
+
+// bufferCloser 记录写入常驻进程 stdin 的请求
+type bufferCloser struct{ bytes.Buffer }
+
+func (b *bufferCloser) Close() error { return nil }
+
+func TestDaemonRequestLine(t *testing.T) {
+	stdin := &bufferCloser{}
+	proc := &daemonProcess{
+		stdin:  stdin,
+		stdout: bufio.NewReader(strings.NewReader(`{"id":1,"status":"ok"}` + "\n")),
+	}
+	options := map[string]string{"sugarenums": "false", "decodelambdas": "false", "hidebridgemethods": ""}
+	if _, err := proc.request("/in/Foo.class", "/out", "com/acme/Foo.java", options); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "1\tdecompile\t/in/Foo.class\t/out\tcom/acme/Foo.java\tdecodelambdas=false\thidebridgemethods\tsugarenums=false\n"
+	if got := stdin.String(); got != want {
+		t.Errorf("request = %q, want %q", got, want)
+	}
+}
+
+func TestWriteDaemonSourceConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon", "EmoradDaemon.java")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("// 旧版本"), 0644)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = writeDaemonSource(path)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, daemonSource) {
+		t.Errorf("daemon source = %d bytes, want %d", len(data), len(daemonSource))
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("残留临时文件: %v", leftovers)
+	}
+}
+
+// fakeDaemon 模拟 java：-cp 方式启动时按 TAB/JSON 协议应答，-jar 方式（独立进程）直接失败
+// 启动、退出和独立进程的调用记录在脚本所在目录的 log 中。
+// 输入名含 Crash 时进程退出，含 Hang 时不再应答，含 Missing 时应答 ok 但不生成源文件
+const fakeDaemon = `#!/bin/sh
+dir=$(dirname "$0")
+if [ "$2" = "-jar" ]; then
+	echo standalone >> "$dir/log"
+	exit 1
+fi
+echo start >> "$dir/log"
+echo '{"id":0,"status":"ready"}'
+tab=$(printf '\t')
+while IFS="$tab" read -r id cmd input out source rest; do
+	if [ "$cmd" = quit ]; then
+		echo quit >> "$dir/log"
+		exit 0
+	fi
+	case "$input" in
+	*Crash*) exit 1 ;;
+	*Hang*) exec sleep 30 ;;
+	*Missing*) ;;
+	*) mkdir -p "$out/$(dirname "$source")" && echo "class {}" > "$out/$source" ;;
+	esac
+	echo "{\"id\":$id,\"status\":\"ok\",\"elapsed\":1}"
+done
+`
+
+// newFakeDaemon 返回使用 fakeDaemon 的 Manager 和记录调用的 log 路径
+func newFakeDaemon(t *testing.T, size int) (*Manager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 shell 脚本模拟 java")
+	}
+	dir := t.TempDir()
+	java := filepath.Join(dir, "java")
+	os.WriteFile(java, []byte(fakeDaemon), 0755)
+	m := &Manager{useJar: true, javaPath: java, cfrPath: filepath.Join(dir, "cfr.jar"), console: console.New(io.Discard, false, "")}
+	if err := m.StartDaemon(size); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m, filepath.Join(dir, "log")
+}
+
+// countLog 返回 log 中等于 event 的行数
+func countLog(t *testing.T, log, event string) int {
+	t.Helper()
+	data, _ := os.ReadFile(log)
+	n := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == event {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDaemonDecompile(t *testing.T) {
+	m, log := newFakeDaemon(t, 2)
+	outputDir := t.TempDir()
+	items := []BatchItem{
+		{InputPath: "/in/Good.class", SourcePath: filepath.Join("com", "acme", "Good.java")},
+		{InputPath: "/in/Missing.class", SourcePath: "Missing.java"},
+	}
+	errs := m.DecompileBatch(context.Background(), items, outputDir)
+	if errs[0] != nil {
+		t.Errorf("Good: %v", errs[0])
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, items[0].SourcePath)); err != nil {
+		t.Errorf("Good source: %v", err)
+	}
+	if errs[1] == nil {
+		t.Error("Missing: daemon replied ok without a source file, want error")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if starts, quits := countLog(t, log, "start"), countLog(t, log, "quit"); starts != 2 || quits != 2 {
+		t.Errorf("start = %d, quit = %d, want 2 and 2", starts, quits)
+	}
+	if m.pool != nil {
+		t.Error("pool not cleared after Close")
+	}
+}
+
+func TestDaemonRestartAfterCrash(t *testing.T) {
+	m, log := newFakeDaemon(t, 1)
+	outputDir := t.TempDir()
+
+	// 崩溃的请求回退到独立进程，fakeDaemon 的独立进程总是失败
+	errs := m.DecompileBatch(context.Background(), []BatchItem{{InputPath: "/in/Crash.class", SourcePath: "Crash.java"}}, outputDir)
+	if errs[0] == nil {
+		t.Error("Crash: want error")
+	}
+	if n := countLog(t, log, "standalone"); n != 1 {
+		t.Errorf("standalone runs = %d, want 1", n)
+	}
+
+	// 下一个请求重新启动该槽位的进程
+	errs = m.DecompileBatch(context.Background(), []BatchItem{{InputPath: "/in/Good.class", SourcePath: "Good.java"}}, outputDir)
+	if errs[0] != nil {
+		t.Errorf("Good after crash: %v", errs[0])
+	}
+	if n := countLog(t, log, "start"); n != 2 {
+		t.Errorf("daemon starts = %d, want 2", n)
+	}
+}
+
+func TestDaemonCancel(t *testing.T) {
+	m, log := newFakeDaemon(t, 1)
+	outputDir := t.TempDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	errs := m.DecompileBatch(ctx, []BatchItem{{InputPath: "/in/Hang.class", SourcePath: "Hang.java"}}, outputDir)
+	if !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("Hang: error = %v, want deadline exceeded", errs[0])
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("cancel took %v, process not killed", elapsed)
+	}
+
+	errs = m.DecompileBatch(context.Background(), []BatchItem{{InputPath: "/in/Good.class", SourcePath: "Good.java"}}, outputDir)
+	if errs[0] != nil {
+		t.Errorf("Good after cancel: %v", errs[0])
+	}
+	if n := countLog(t, log, "start"); n != 2 {
+		t.Errorf("daemon starts = %d, want 2", n)
+	}
+}
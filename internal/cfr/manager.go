@@ -1,51 +1,103 @@
 package cfr
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/classfile"
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+	"github.com/jiaozhu/emorad/internal/i18n"
 )
 
 const (
 	// CFR下载地址
 	DownloadURL = "https://github.com/leibnitz27/cfr/releases/download/0.152/cfr-0.152.jar"
 	Version     = "0.152"
+	// SHA256 官方 cfr-0.152.jar 的 SHA-256，下载、安装和使用缓存的 JAR 时都必须一致；
+	// 为空时不使用下载或缓存的 JAR，只能通过 JarPath、EMORAD_CFR_JAR 或 cfr-decompiler 命令运行
+	SHA256 = ""
+	// MavenCoords CFR 在 Maven 仓库中的坐标，用于从 Nexus/Artifactory 等镜像下载
+	MavenCoords = "org.benf:cfr:0.152"
+	// JarEnv 指定 CFR JAR 路径的环境变量
+	JarEnv = "EMORAD_CFR_JAR"
 )
 
+// pinnedSHA256 校验 CFR JAR 使用的摘要，测试中替换
+var pinnedSHA256 = SHA256
+
+// Options 创建 Manager 的配置
+type Options struct {
+	JarPath  string            // 指定 CFR JAR 路径，为空时自动查找或下载
+	Download download.Options  // 下载 CFR 时使用的镜像、代理和重试配置
+	Options  map[string]string // 传给 CFR 的选项，如 decodelambdas=false
+	Console  *console.Console  // 控制台输出，nil 时写入 color.Output
+}
+
 // Manager 管理CFR反编译器
 type Manager struct {
 	cfrPath  string // CFR JAR文件路径或命令路径
 	useJar   bool   // 是否使用JAR文件
 	javaPath string // Java命令路径
+
+	pool      *daemonPool // 常驻进程池，未启用时为 nil
+	daemonSrc string      // 常驻进程源码路径
+
+	download download.Options  // 下载配置
+	options  map[string]string // CFR 选项
+	console  *console.Console  // 控制台输出
 }
 
 // NewManager 创建CFR管理器
-func NewManager() (*Manager, error) {
-	manager := &Manager{}
+// opts.JarPath 指定 CFR JAR 路径；为空时依次尝试环境变量 EMORAD_CFR_JAR、
+// 系统 cfr-decompiler 命令和 ~/.emorad/cfr 下缓存的 JAR
+func NewManager(opts Options) (*Manager, error) {
+	manager := &Manager{download: opts.Download, options: opts.Options, console: opts.Console}
+
+	jarPath := opts.JarPath
+	if jarPath == "" {
+		jarPath = os.Getenv(JarEnv)
+	}
+
+	// 显式指定的 JAR 优先，不做下载
+	if jarPath != "" {
+		javaPath, err := exec.LookPath("java")
+		if err != nil {
+			return nil, fmt.Errorf(manager.console.T("未找到Java环境,请安装Java: %v"), err)
+		}
+		if err := download.CheckJar(jarPath); err != nil {
+			return nil, fmt.Errorf(manager.console.T("指定的CFR JAR不可用 %s: %v"), jarPath, err)
+		}
+		manager.javaPath = javaPath
+		manager.cfrPath = jarPath
+		manager.useJar = true
+		manager.console.Green(manager.console.T("✓ 使用指定的CFR JAR: %s"), jarPath)
+		return manager, nil
+	}
 
 	// 首先尝试使用系统安装的cfr-decompiler命令
 	if path, err := exec.LookPath("cfr-decompiler"); err == nil {
-		color.Green("✓ 找到系统CFR命令: %s", path)
+		manager.console.Green(manager.console.T("✓ 找到系统CFR命令: %s"), path)
 		manager.cfrPath = path
 		manager.useJar = false
 		return manager, nil
 	}
 
 	// 如果没有系统命令,尝试使用Java运行CFR JAR
-	color.Yellow("系统未安装CFR命令,尝试使用CFR JAR文件...")
+	manager.console.Yellow(manager.console.T("系统未安装CFR命令,尝试使用CFR JAR文件..."))
 
 	// 检查Java是否可用
 	javaPath, err := exec.LookPath("java")
 	if err != nil {
-		return nil, fmt.Errorf("未找到Java环境,请安装Java: %v", err)
+		return nil, fmt.Errorf(manager.console.T("未找到Java环境,请安装Java: %v"), err)
 	}
 	manager.javaPath = javaPath
 
@@ -57,93 +109,118 @@ func NewManager() (*Manager, error) {
 
 	manager.cfrPath = cfrJarPath
 	manager.useJar = true
-	color.Green("✓ 使用CFR JAR: %s", cfrJarPath)
+	manager.console.Green(manager.console.T("✓ 使用CFR JAR: %s"), cfrJarPath)
 
 	return manager, nil
 }
 
-// ensureCFRJar 确保CFR JAR文件存在,如果不存在则下载
-func (m *Manager) ensureCFRJar() (string, error) {
-	// 确定CFR存储目录
+// JarPath 返回缓存的CFR JAR路径 (~/.emorad/cfr/cfr-<version>.jar)
+func JarPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("无法获取用户主目录: %v", err)
+		return "", fmt.Errorf(i18n.T("无法获取用户主目录: %v"), err)
 	}
+	return filepath.Join(homeDir, ".emorad", "cfr", fmt.Sprintf("cfr-%s.jar", Version)), nil
+}
 
-	cfrDir := filepath.Join(homeDir, ".emorad", "cfr")
-	if err := os.MkdirAll(cfrDir, 0755); err != nil {
-		return "", fmt.Errorf("创建CFR目录失败: %v", err)
+// Install 从本地文件安装CFR JAR,用于无法联网的环境
+func Install(srcPath string) (string, error) {
+	cfrJarPath, err := JarPath()
+	if err != nil {
+		return "", err
 	}
+	if err := download.Install(srcPath, cfrJarPath, pinnedSHA256); err != nil {
+		return "", fmt.Errorf(i18n.T("安装CFR失败: %v"), err)
+	}
+	return cfrJarPath, nil
+}
 
-	cfrJarPath := filepath.Join(cfrDir, fmt.Sprintf("cfr-%s.jar", Version))
+// ensureCFRJar 确保CFR JAR文件存在且与固定的 SHA-256 一致,否则重新下载
+// 未固定 SHA-256 时不使用缓存，也不下载
+func (m *Manager) ensureCFRJar() (string, error) {
+	if pinnedSHA256 == "" {
+		return "", fmt.Errorf(m.console.T("CFR v%s 未固定 SHA-256，不能使用下载或缓存的 JAR，请通过 --cfr-jar 或 EMORAD_CFR_JAR 指定"), Version)
+	}
+	cfrJarPath, err := JarPath()
+	if err != nil {
+		return "", err
+	}
 
-	// 检查文件是否存在
+	// 检查文件是否存在且校验通过
 	if _, err := os.Stat(cfrJarPath); err == nil {
-		return cfrJarPath, nil
+		err := download.Verify(cfrJarPath, pinnedSHA256)
+		if err == nil {
+			return cfrJarPath, nil
+		}
+		m.console.Warn(m.console.T("[WARN] 缓存的CFR JAR校验失败,重新下载: %v"), err)
 	}
 
 	// 下载CFR JAR
-	color.Cyan("正在下载CFR反编译器 v%s...", Version)
+	m.console.Cyan(m.console.T("正在下载CFR反编译器 v%s..."), Version)
 	if err := m.downloadCFR(cfrJarPath); err != nil {
 		return "", err
 	}
 
-	color.Green("✓ CFR下载完成")
+	m.console.Green(m.console.T("✓ CFR下载完成"))
 	return cfrJarPath, nil
 }
 
-// downloadCFR 下载CFR JAR文件
+// downloadCFR 下载CFR JAR文件,依次尝试镜像、官方地址和 Maven 中央仓库
+// 校验通过后才写入目标路径
 func (m *Manager) downloadCFR(destPath string) error {
-	client := &http.Client{
-		Timeout: 60 * time.Second,
+	artifact := download.Artifact{URL: DownloadURL, Maven: MavenCoords, SHA256: pinnedSHA256}
+	if err := download.Fetch(artifact, destPath, m.download); err != nil {
+		return fmt.Errorf(m.console.T("下载CFR失败: %v"), err)
 	}
-	resp, err := client.Get(DownloadURL)
-	if err != nil {
-		return fmt.Errorf("下载CFR失败: %v", err)
-	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载CFR失败,状态码: %d", resp.StatusCode)
-	}
+// Decompile 反编译class文件或JAR文件，ctx 取消时终止 CFR 进程
+// 使用创建 Manager 时配置的 CFR 选项
+func (m *Manager) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	return m.DecompileWithOptions(ctx, inputPath, outputDir, m.options)
+}
 
-	out, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %v", err)
+// DecompileWithOptions 使用自定义选项反编译，options 为 nil 时使用 CFR 默认选项
+func (m *Manager) DecompileWithOptions(ctx context.Context, inputPath string, outputDir string, options map[string]string) error {
+	return m.decompileOne(ctx, inputPath, expectedSource(inputPath), outputDir, options)
+}
+
+// decompileOne 通过常驻进程或独立进程反编译单个文件
+// sourcePath 为预期生成的源文件（相对于输出目录），不为空时先删除已有的文件，
+// CFR 正常结束但没有生成该文件时视为失败；为空时不检查
+func (m *Manager) decompileOne(ctx context.Context, inputPath, sourcePath, outputDir string, options map[string]string) error {
+	if sourcePath != "" {
+		if err := removeStale(filepath.Join(outputDir, sourcePath)); err != nil {
+			return err
+		}
 	}
-	defer out.Close()
 
-	// 显示下载进度
-	_, err = io.Copy(out, resp.Body)
+	err := errDaemonUnavailable
+	if m.pool != nil {
+		err = m.pool.decompile(ctx, inputPath, outputDir, sourcePath, options)
+	}
+	if err == errDaemonUnavailable {
+		err = m.run(ctx, inputPath, outputDir, options)
+	}
 	if err != nil {
-		return fmt.Errorf("保存文件失败: %v", err)
+		return err
+	}
+	if sourcePath != "" && !sourceExists(filepath.Join(outputDir, sourcePath)) {
+		return fmt.Errorf(m.console.T("未生成源文件: %s"), filepath.ToSlash(sourcePath))
 	}
-
 	return nil
 }
 
-// Decompile 反编译class文件或JAR文件
-func (m *Manager) Decompile(inputPath string, outputDir string) error {
-	var cmd *exec.Cmd
-
-	if m.useJar {
-		// 使用Java运行CFR JAR
-		// 添加 -Dfile.encoding=UTF-8 确保输出使用UTF-8编码，解决中文乱码问题
-		args := []string{
-			"-Dfile.encoding=UTF-8",
-			"-jar", m.cfrPath,
-			inputPath,
-			"--outputdir", outputDir,
-			"--caseinsensitivefs", "true", // Windows兼容
-		}
-		cmd = exec.Command(m.javaPath, args...)
-	} else {
-		// 使用系统CFR命令
-		cmd = exec.Command(m.cfrPath, inputPath, "--outputdir", outputDir)
-	}
+// run 在独立的 CFR 进程中反编译
+func (m *Manager) run(ctx context.Context, inputPath string, outputDir string, options map[string]string) error {
+	cmd := m.command(ctx, []string{inputPath}, outputDir, options)
 
 	// 捕获输出
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		return fmt.Errorf("%v: %s", err, string(output))
 	}
@@ -151,46 +228,185 @@ func (m *Manager) Decompile(inputPath string, outputDir string) error {
 	return nil
 }
 
-// DecompileWithOptions 使用自定义选项反编译
-func (m *Manager) DecompileWithOptions(inputPath string, outputDir string, options map[string]string) error {
-	var args []string
-
-	if m.useJar {
-		// 添加 -Dfile.encoding=UTF-8 确保输出使用UTF-8编码，解决中文乱码问题
-		args = append(args, "-Dfile.encoding=UTF-8", "-jar", m.cfrPath)
+// command 构造运行 CFR 的命令
+// JAR 模式通过 java -jar 运行，系统命令模式直接将参数传给 cfr-decompiler
+func (m *Manager) command(ctx context.Context, inputs []string, outputDir string, options map[string]string) *exec.Cmd {
+	args := cfrArgs(inputs, outputDir, options)
+	if !m.useJar {
+		return exec.CommandContext(ctx, m.cfrPath, args...)
 	}
+	// 添加 -Dfile.encoding=UTF-8 确保输出使用UTF-8编码，解决中文乱码问题
+	args = append([]string{"-Dfile.encoding=UTF-8", "-jar", m.cfrPath}, args...)
+	return exec.CommandContext(ctx, m.javaPath, args...)
+}
 
-	args = append(args, inputPath, "--outputdir", outputDir)
+// cfrArgs 生成 CFR 参数：输入文件、输出目录和按名称排序的选项
+// 未指定 caseinsensitivefs 时默认开启，兼容 Windows 等大小写不敏感的文件系统
+func cfrArgs(inputs []string, outputDir string, options map[string]string) []string {
+	args := append([]string{}, inputs...)
+	args = append(args, "--outputdir", outputDir)
 
-	// 添加自定义选项
-	for key, value := range options {
-		if value == "" {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := options[key]; value == "" {
 			args = append(args, "--"+key)
 		} else {
 			args = append(args, "--"+key, value)
 		}
 	}
 
-	// Windows兼容性
-	if runtime.GOOS == "windows" {
+	if _, ok := options["caseinsensitivefs"]; !ok {
 		args = append(args, "--caseinsensitivefs", "true")
 	}
+	return args
+}
 
-	var cmd *exec.Cmd
-	if m.useJar {
-		cmd = exec.Command(m.javaPath, args...)
-	} else {
-		cmd = exec.Command(m.cfrPath, args[1:]...) // 跳过jar参数
+// ParseOptions 解析 key=value 形式的 CFR 选项，键名前的 -- 可省略
+// 只写键名时视为 key=true；outputdir 由 emorad 管理，不允许覆盖
+func ParseOptions(values []string) (map[string]string, error) {
+	options := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, found := strings.Cut(v, "=")
+		key = strings.TrimPrefix(strings.TrimSpace(key), "--")
+		if key == "" {
+			return nil, fmt.Errorf(i18n.T("无效的CFR选项: %q"), v)
+		}
+		if key == "outputdir" {
+			return nil, fmt.Errorf(i18n.T("CFR选项 outputdir 由 --output 控制，不能单独指定"))
+		}
+		if !found {
+			value = "true"
+		}
+		options[key] = strings.TrimSpace(value)
 	}
+	return options, nil
+}
 
-	output, err := cmd.CombinedOutput()
+// maxBatchArgBytes 单个 CFR 进程命令行参数总长度上限
+// Windows 命令行最长 32767 字符，其他平台留有余量即可
+var maxBatchArgBytes = func() int {
+	if runtime.GOOS == "windows" {
+		return 30000
+	}
+	return 512 * 1024
+}()
+
+// BatchItem 批量反编译中的单个 class 文件
+type BatchItem struct {
+	InputPath  string // class 文件路径
+	SourcePath string // 预期生成的 .java 文件(相对于输出目录)
+}
+
+// DecompileBatch 在同一个 CFR 进程中反编译一组 class 文件
+// 返回的错误切片与 items 一一对应，nil 表示该 class 反编译成功。
+// 参数过长时会自动拆分为多个进程执行。
+func (m *Manager) DecompileBatch(ctx context.Context, items []BatchItem, outputDir string) []error {
+	errs := make([]error, len(items))
+
+	// 常驻进程已避免 JVM 启动开销，逐个提交即可获得准确的单个结果
+	if m.pool != nil {
+		for i, item := range items {
+			errs[i] = m.decompileOne(ctx, item.InputPath, item.SourcePath, outputDir, m.options)
+		}
+		return errs
+	}
+
+	for _, chunk := range splitBatch(items, maxBatchArgBytes) {
+		m.decompileChunk(ctx, items[chunk[0]:chunk[1]], outputDir, errs[chunk[0]:chunk[1]])
+	}
+	return errs
+}
+
+// decompileChunk 启动一个 CFR 进程处理一段 class 文件，结果写入 errs
+func (m *Manager) decompileChunk(ctx context.Context, items []BatchItem, outputDir string, errs []error) {
+	inputs := make([]string, len(items))
+	for i, item := range items {
+		inputs[i] = item.InputPath
+	}
+	cmd := m.command(ctx, inputs, outputDir, m.options)
+
+	// CFR 对单个 class 失败不一定返回非零退出码，因此以预期的 .java 文件是否生成作为判断依据；
+	// 先删除之前运行留下的同名文件，不依赖文件的修改时间
+	for i, item := range items {
+		if err := removeStale(filepath.Join(outputDir, item.SourcePath)); err != nil {
+			errs[i] = err
+		}
+	}
+	output, runErr := cmd.CombinedOutput()
+
+	for i, item := range items {
+		if errs[i] != nil {
+			continue
+		}
+		if sourceExists(filepath.Join(outputDir, item.SourcePath)) {
+			continue
+		}
+		switch {
+		case ctx.Err() != nil:
+			errs[i] = ctx.Err()
+		case runErr != nil:
+			errs[i] = fmt.Errorf("%v: %s", runErr, strings.TrimSpace(string(output)))
+		default:
+			errs[i] = fmt.Errorf(m.console.T("未生成源文件: %s"), filepath.ToSlash(item.SourcePath))
+		}
+	}
+}
+
+// expectedSource 根据 class 文件中的类名推算 CFR 生成的源文件（相对于输出目录）
+// 不是 class 文件、内部类或 package-info、module-info 时返回空，不检查输出
+func expectedSource(inputPath string) string {
+	cf, err := classfile.ParseFile(inputPath)
 	if err != nil {
-		return fmt.Errorf("%v: %s", err, string(output))
+		return ""
+	}
+	name := path.Base(cf.ThisClass)
+	if strings.Contains(name, "$") || strings.HasSuffix(name, "-info") {
+		return ""
 	}
+	return filepath.FromSlash(cf.ThisClass + ".java")
+}
 
+// removeStale 删除预期输出位置上已有的源文件，不存在时不报错
+func removeStale(sourcePath string) error {
+	if err := os.Remove(sourcePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	return nil
 }
 
+// sourceExists 判断反编译是否生成了预期的源文件
+func sourceExists(sourcePath string) bool {
+	info, err := os.Stat(sourcePath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// splitBatch 按命令行长度拆分批次，返回每段的 [起始, 结束) 下标
+func splitBatch(items []BatchItem, maxArgBytes int) [][2]int {
+	var chunks [][2]int
+	start, size := 0, 0
+	for i, item := range items {
+		n := len(item.InputPath) + 1
+		if i > start && size+n > maxArgBytes {
+			chunks = append(chunks, [2]int{start, i})
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(items) {
+		chunks = append(chunks, [2]int{start, len(items)})
+	}
+	return chunks
+}
+
+// Path 返回使用的 CFR JAR 或 cfr-decompiler 命令的路径
+func (m *Manager) Path() string {
+	return m.cfrPath
+}
+
 // GetVersion 获取CFR版本信息
 func (m *Manager) GetVersion() (string, error) {
 	var cmd *exec.Cmd
@@ -214,10 +430,10 @@ func CheckJavaInstallation() error {
 	cmd := exec.Command("java", "-version")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("Java未安装或不在PATH中\n请安装Java: https://www.java.com/\n错误: %v", err)
+		return fmt.Errorf(i18n.T("Java未安装或不在PATH中\n请安装Java: https://www.java.com/\n错误: %v"), err)
 	}
 
-	color.Green("✓ Java环境检测成功")
+	color.Green(i18n.T("✓ Java环境检测成功"))
 	// 输出Java版本信息
 	lines := strings.Split(string(output), "\n")
 	if len(lines) > 0 {
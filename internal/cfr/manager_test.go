@@ -0,0 +1,269 @@
+package cfr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+)
+
+func TestSplitBatch(t *testing.T) {
+	items := []BatchItem{
+		{InputPath: "aaaa"},
+		{InputPath: "bbbb"},
+		{InputPath: "cccc"},
+		{InputPath: "dddddddddddd"},
+	}
+
+	tests := []struct {
+		name     string
+		max      int
+		expected [][2]int
+	}{
+		{"全部放入一批", 1024, [][2]int{{0, 4}}},
+		{"按长度拆分", 10, [][2]int{{0, 2}, {2, 3}, {3, 4}}},
+		{"单项超长也单独成批", 1, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := splitBatch(items, tt.max)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("splitBatch() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	if result := splitBatch(nil, 10); len(result) != 0 {
+		t.Errorf("splitBatch(nil) = %v, want empty", result)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{"键值对", []string{"decodelambdas=false", "--renamedupmembers=true"}, map[string]string{"decodelambdas": "false", "renamedupmembers": "true"}, false},
+		{"只写键名", []string{"hidebridgemethods"}, map[string]string{"hidebridgemethods": "true"}, false},
+		{"后者覆盖前者", []string{"sugarenums=true", "sugarenums=false"}, map[string]string{"sugarenums": "false"}, false},
+		{"空键名", []string{"=true"}, nil, true},
+		{"禁止覆盖输出目录", []string{"outputdir=/tmp"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseOptions(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseOptions() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCFRArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string]string
+		expected []string
+	}{
+		{"默认选项", nil, []string{"A.class", "--outputdir", "out", "--caseinsensitivefs", "true"}},
+		{"选项按名称排序", map[string]string{"sugarenums": "false", "decodelambdas": "false"},
+			[]string{"A.class", "--outputdir", "out", "--decodelambdas", "false", "--sugarenums", "false", "--caseinsensitivefs", "true"}},
+		{"覆盖大小写选项", map[string]string{"caseinsensitivefs": "false"},
+			[]string{"A.class", "--outputdir", "out", "--caseinsensitivefs", "false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := cfrArgs([]string{"A.class"}, "out", tt.options)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("cfrArgs() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// buildJar 生成结构完整的 JAR，content 不同则摘要不同
+func buildJar(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("META-INF/MANIFEST.MF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(content))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// roundTripFunc 不访问网络，所有请求都由函数应答
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestEnsureCFRJarDigest(t *testing.T) {
+	official := buildJar(t, "Manifest-Version: 1.0\n")
+	tampered := buildJar(t, "Manifest-Version: 1.0\nX-Tampered: true\n")
+	sum := sha256.Sum256(official)
+	defer func(old string) { pinnedSHA256 = old }(pinnedSHA256)
+	pinnedSHA256 = hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		cached  []byte // ~/.emorad/cfr 下已有的 JAR
+		served  []byte // 下载地址返回的内容
+		wantErr bool
+	}{
+		{"缓存与固定摘要一致", official, nil, false},
+		{"缓存被篡改时重新下载", tampered, official, false},
+		{"下载的 JAR 被篡改", tampered, tampered, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			path, err := JarPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, tt.cached, 0644)
+
+			requests := 0
+			client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				requests++
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(tt.served)), Request: r}, nil
+			})}
+			m := &Manager{download: download.Options{Client: client}}
+
+			_, err = m.ensureCFRJar()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureCFRJar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.served == nil && requests > 0 {
+				t.Errorf("downloaded %d times with a valid cache", requests)
+			}
+			if err := download.Verify(path, pinnedSHA256); !tt.wantErr && err != nil {
+				t.Errorf("cached JAR not replaced: %v", err)
+			}
+		})
+	}
+
+	t.Run("安装被篡改的 JAR", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("USERPROFILE", home)
+		src := filepath.Join(home, "cfr.jar")
+		os.WriteFile(src, tampered, 0644)
+		if _, err := Install(src); err == nil {
+			t.Error("Install() accepted a JAR that does not match the pinned digest")
+		}
+	})
+}
+
+// TestNewManagerProductionDigest 使用代码中固定的 SHA256，下载和缓存的 JAR 与之不一致时拒绝运行
+func TestNewManagerProductionDigest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 shell 脚本模拟 java")
+	}
+	if pinnedSHA256 != SHA256 {
+		t.Fatalf("pinnedSHA256 = %q, want production SHA256 %q", pinnedSHA256, SHA256)
+	}
+	bin := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "java"), []byte("#!/bin/sh\nexit 0\n"), 0755)
+	t.Setenv("PATH", bin)
+	t.Setenv(JarEnv, "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	unofficial := buildJar(t, "Manifest-Version: 1.0\nCreated-By: mirror\n")
+	path, err := JarPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, unofficial, 0644)
+
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(unofficial)), Request: r}, nil
+	})}
+	m, err := NewManager(Options{
+		Download: download.Options{Client: client, Mirrors: []string{"https://mirror.example.com/{file}"}},
+		Console:  console.New(io.Discard, false, ""),
+	})
+	if err == nil {
+		t.Fatalf("NewManager() used an unverified JAR: %s", m.Path())
+	}
+}
+
+// fakeCFR 模拟 java -jar cfr.jar <class...> --outputdir <dir> ...：
+// 为文件名不含 Broken 的 class 生成同名 .java，Broken 的 class 只输出错误信息，退出码为 0
+const fakeCFR = `#!/bin/sh
+shift 3
+out=
+inputs=
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--outputdir) out="$2"; shift 2 ;;
+	--*) shift 2 ;;
+	*) inputs="$inputs $1"; shift ;;
+	esac
+done
+for input in $inputs; do
+	name=$(basename "$input" .class)
+	case "$name" in
+	*Broken*) echo "Exception decompiling $name" ;;
+	*) echo "class $name {}" > "$out/$name.java" ;;
+	esac
+done
+`
+
+func TestDecompileChunkResults(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 shell 脚本模拟 java")
+	}
+	dir := t.TempDir()
+	java := filepath.Join(dir, "java")
+	os.WriteFile(java, []byte(fakeCFR), 0755)
+	outputDir := filepath.Join(dir, "out")
+	os.MkdirAll(outputDir, 0755)
+	// 同一秒内上次运行留下的源文件不应被当作本次的结果
+	os.WriteFile(filepath.Join(outputDir, "Broken.java"), []byte("class Broken {}"), 0644)
+
+	var items []BatchItem
+	for _, name := range []string{"Good", "Broken", "Other", "AlsoBroken"} {
+		items = append(items, BatchItem{InputPath: filepath.Join(dir, name+".class"), SourcePath: name + ".java"})
+	}
+	m := &Manager{useJar: true, javaPath: java, cfrPath: filepath.Join(dir, "cfr.jar"), console: console.New(io.Discard, false, "")}
+	errs := m.DecompileBatch(context.Background(), items, outputDir)
+
+	for i, item := range items {
+		wantErr := strings.Contains(item.SourcePath, "Broken")
+		if (errs[i] != nil) != wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", item.SourcePath, errs[i], wantErr)
+		}
+	}
+}
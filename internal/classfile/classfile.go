@@ -0,0 +1,368 @@
+package classfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// magic class 文件头
+const magic = 0xCAFEBABE
+
+// 类的访问标志
+const (
+	AccPublic     = 0x0001
+	AccFinal      = 0x0010
+	AccSuper      = 0x0020
+	AccInterface  = 0x0200
+	AccAbstract   = 0x0400
+	AccSynthetic  = 0x1000
+	AccAnnotation = 0x2000
+	AccEnum       = 0x4000
+	AccModule     = 0x8000
+)
+
+// 常量池标签
+const (
+	tagUtf8               = 1
+	tagInteger            = 3
+	tagFloat              = 4
+	tagLong               = 5
+	tagDouble             = 6
+	tagClass              = 7
+	tagString             = 8
+	tagFieldref           = 9
+	tagMethodref          = 10
+	tagInterfaceMethodref = 11
+	tagNameAndType        = 12
+	tagMethodHandle       = 15
+	tagMethodType         = 16
+	tagDynamic            = 17
+	tagInvokeDynamic      = 18
+	tagModule             = 19
+	tagPackage            = 20
+)
+
+// maxAttributeSize 单次读取的最大长度，防止损坏的文件导致分配过多内存
+const maxAttributeSize = 16 << 20
+
+// ErrNotClassFile 文件头不是 0xCAFEBABE
+var ErrNotClassFile error = i18n.Error("不是有效的 class 文件")
+
+// ClassFile class 文件中的类元数据
+// 类名使用 JVM 内部形式，如 com/acme/Foo$Bar
+type ClassFile struct {
+	MinorVersion uint16
+	MajorVersion uint16
+	AccessFlags  uint16
+	ThisClass    string
+	SuperClass   string // java/lang/Object 和 module-info 为空
+	Interfaces   []string
+	Annotations  []string // 类上的注解类型，包括运行时不可见的注解
+	SourceFile   string   // SourceFile 属性，编译时未保留则为空
+}
+
+// ParseFile 解析 class 文件
+func ParseFile(path string) (*ClassFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse 从 r 中解析 class 文件，只读取到类属性为止
+func Parse(r io.Reader) (*ClassFile, error) {
+	p := &parser{r: bufio.NewReader(r)}
+	cf, err := p.parse()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf(i18n.T("class 文件不完整"))
+		}
+		return nil, err
+	}
+	return cf, nil
+}
+
+// ClassName 返回类的全限定名，如 com.acme.Foo$Bar
+func (c *ClassFile) ClassName() string {
+	return strings.ReplaceAll(c.ThisClass, "/", ".")
+}
+
+// PackageName 返回以 / 分隔的包路径，默认包为空
+func (c *ClassFile) PackageName() string {
+	if idx := strings.LastIndex(c.ThisClass, "/"); idx != -1 {
+		return c.ThisClass[:idx]
+	}
+	return ""
+}
+
+// JavaVersion 返回编译目标的 Java 版本，如 major 52 对应 Java 8
+func (c *ClassFile) JavaVersion() int {
+	return JavaVersion(c.MajorVersion)
+}
+
+// JavaVersion 将 class 文件主版本号转换为 Java 版本，如 52 对应 8
+// Java 1.4 及更早的版本返回 1，无效的版本号返回 0
+func JavaVersion(major uint16) int {
+	switch {
+	case major < 45:
+		return 0
+	case major < 49:
+		return 1
+	default:
+		return int(major) - 44
+	}
+}
+
+// Kind 返回类的种类：class、interface、annotation、enum、module
+func (c *ClassFile) Kind() string {
+	switch {
+	case c.AccessFlags&AccModule != 0:
+		return "module"
+	case c.AccessFlags&AccAnnotation != 0:
+		return "annotation"
+	case c.AccessFlags&AccInterface != 0:
+		return "interface"
+	case c.AccessFlags&AccEnum != 0:
+		return "enum"
+	default:
+		return "class"
+	}
+}
+
+// constant 常量池中的一项，只保留解析类元数据所需的内容
+type constant struct {
+	tag   uint8
+	utf8  string // Utf8
+	index uint16 // Class、Module、Package 指向的 Utf8 下标
+}
+
+type parser struct {
+	r    *bufio.Reader
+	pool []constant
+	err  error
+}
+
+func (p *parser) parse() (*ClassFile, error) {
+	if p.u4() != magic {
+		if p.err != nil {
+			return nil, p.err
+		}
+		return nil, ErrNotClassFile
+	}
+
+	cf := &ClassFile{}
+	cf.MinorVersion = p.u2()
+	cf.MajorVersion = p.u2()
+	if err := p.readConstantPool(); err != nil {
+		return nil, err
+	}
+
+	cf.AccessFlags = p.u2()
+	cf.ThisClass = p.className(p.u2())
+	cf.SuperClass = p.className(p.u2())
+	count := p.u2()
+	for i := 0; i < int(count) && p.err == nil; i++ {
+		cf.Interfaces = append(cf.Interfaces, p.className(p.u2()))
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if cf.ThisClass == "" {
+		return nil, fmt.Errorf(i18n.T("无效的 this_class"))
+	}
+
+	// 跳过字段和方法
+	for member := 0; member < 2; member++ {
+		count := p.u2()
+		for i := 0; i < int(count) && p.err == nil; i++ {
+			p.skip(6) // access_flags、name_index、descriptor_index
+			p.skipAttributes()
+		}
+	}
+
+	count = p.u2()
+	for i := 0; i < int(count) && p.err == nil; i++ {
+		name := p.utf8(p.u2())
+		length := p.u4()
+		switch name {
+		case "RuntimeVisibleAnnotations", "RuntimeInvisibleAnnotations":
+			data := p.bytes(length)
+			if p.err == nil {
+				cf.Annotations = append(cf.Annotations, p.annotationTypes(data)...)
+			}
+		case "SourceFile":
+			cf.SourceFile = p.utf8(p.u2())
+		default:
+			p.skip(int(length))
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return cf, nil
+}
+
+// readConstantPool 读取常量池，下标从 1 开始，long 和 double 占两个位置
+func (p *parser) readConstantPool() error {
+	count := p.u2()
+	p.pool = make([]constant, count)
+	for i := 1; i < int(count) && p.err == nil; i++ {
+		c := constant{tag: p.u1()}
+		wide := false
+		switch c.tag {
+		case tagUtf8:
+			c.utf8 = decodeModifiedUTF8(p.bytes(uint32(p.u2())))
+		case tagClass, tagModule, tagPackage:
+			c.index = p.u2()
+		case tagString, tagMethodType:
+			p.skip(2)
+		case tagMethodHandle:
+			p.skip(3)
+		case tagInteger, tagFloat, tagFieldref, tagMethodref, tagInterfaceMethodref,
+			tagNameAndType, tagDynamic, tagInvokeDynamic:
+			p.skip(4)
+		case tagLong, tagDouble:
+			p.skip(8)
+			wide = true
+		default:
+			if p.err == nil {
+				return fmt.Errorf(i18n.T("未知的常量池标签 %d (位置 %d)"), c.tag, i)
+			}
+		}
+		p.pool[i] = c
+		if wide {
+			i++
+		}
+	}
+	return p.err
+}
+
+// utf8 返回常量池中的字符串
+func (p *parser) utf8(index uint16) string {
+	if int(index) >= len(p.pool) || p.pool[index].tag != tagUtf8 {
+		return ""
+	}
+	return p.pool[index].utf8
+}
+
+// className 返回 CONSTANT_Class 指向的类名，下标为 0 时返回空
+func (p *parser) className(index uint16) string {
+	if int(index) >= len(p.pool) || p.pool[index].tag != tagClass {
+		return ""
+	}
+	return p.utf8(p.pool[index].index)
+}
+
+// annotationTypes 解析注解属性，返回注解的类型名
+func (p *parser) annotationTypes(data []byte) []string {
+	a := &parser{r: bufio.NewReader(bytes.NewReader(data)), pool: p.pool}
+	count := a.u2()
+	types := make([]string, 0, count)
+	for i := 0; i < int(count) && a.err == nil; i++ {
+		if name := a.annotation(); a.err == nil {
+			types = append(types, name)
+		}
+	}
+	return types
+}
+
+// annotation 读取一个注解并返回其类型名，如 org/springframework/stereotype/Service
+func (p *parser) annotation() string {
+	descriptor := p.utf8(p.u2())
+	pairs := p.u2()
+	for i := 0; i < int(pairs) && p.err == nil; i++ {
+		p.skip(2) // element_name_index
+		p.skipElementValue()
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(descriptor, "L"), ";")
+}
+
+// skipElementValue 跳过注解元素的值
+func (p *parser) skipElementValue() {
+	switch tag := p.u1(); tag {
+	case 'B', 'C', 'D', 'F', 'I', 'J', 'S', 'Z', 's', 'c':
+		p.skip(2)
+	case 'e':
+		p.skip(4)
+	case '@':
+		p.annotation()
+	case '[':
+		count := p.u2()
+		for i := 0; i < int(count) && p.err == nil; i++ {
+			p.skipElementValue()
+		}
+	default:
+		if p.err == nil {
+			p.err = fmt.Errorf(i18n.T("未知的注解元素类型 %q"), tag)
+		}
+	}
+}
+
+// skipAttributes 跳过一组属性
+func (p *parser) skipAttributes() {
+	count := p.u2()
+	for i := 0; i < int(count) && p.err == nil; i++ {
+		p.skip(2)
+		p.skip(int(p.u4()))
+	}
+}
+
+func (p *parser) u1() uint8 {
+	b := p.bytes(1)
+	if p.err != nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (p *parser) u2() uint16 {
+	b := p.bytes(2)
+	if p.err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (p *parser) u4() uint32 {
+	b := p.bytes(4)
+	if p.err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (p *parser) bytes(n uint32) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if n > maxAttributeSize {
+		p.err = fmt.Errorf(i18n.T("属性长度 %d 超出范围"), n)
+		return nil
+	}
+	b := make([]byte, n)
+	_, p.err = io.ReadFull(p.r, b)
+	return b
+}
+
+func (p *parser) skip(n int) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = p.r.Discard(n)
+}
+
+// decodeModifiedUTF8 解码 class 文件使用的 Modified UTF-8
+// 与标准 UTF-8 的区别仅在 \u0000 和增补字符的编码，类名中几乎不会出现，
+// 因此只处理 \u0000 的双字节形式，其余按标准 UTF-8 解释
+func decodeModifiedUTF8(b []byte) string {
+	return strings.ReplaceAll(string(b), "\xc0\x80", "\x00")
+}
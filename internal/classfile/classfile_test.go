@@ -0,0 +1,159 @@
+package classfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// classBuilder 按 JVM 规范拼装测试用的 class 文件
+type classBuilder struct {
+	bytes.Buffer
+}
+
+func (b *classBuilder) u1(v uint8)  { b.WriteByte(v) }
+func (b *classBuilder) u2(v uint16) { binary.Write(b, binary.BigEndian, v) }
+func (b *classBuilder) u4(v uint32) { binary.Write(b, binary.BigEndian, v) }
+
+func (b *classBuilder) utf8(s string) {
+	b.u1(tagUtf8)
+	b.u2(uint16(len(s)))
+	b.WriteString(s)
+}
+
+func (b *classBuilder) class(nameIndex uint16) {
+	b.u1(tagClass)
+	b.u2(nameIndex)
+}
+
+func buildClass() []byte {
+	var b classBuilder
+	b.u4(magic)
+	b.u2(0)  // minor
+	b.u2(52) // major: Java 8
+
+	b.u2(15)
+	b.utf8("com/acme/Foo")                             // 1
+	b.class(1)                                         // 2
+	b.utf8("java/lang/Object")                         // 3
+	b.class(3)                                         // 4
+	b.utf8("java/io/Serializable")                     // 5
+	b.class(5)                                         // 6
+	b.u1(tagLong)                                      // 7, 8
+	b.u4(0)                                            //
+	b.u4(42)                                           //
+	b.utf8("RuntimeVisibleAnnotations")                // 9
+	b.utf8("Lorg/springframework/stereotype/Service;") // 10
+	b.utf8("value")                                    // 11
+	b.utf8("SourceFile")                               // 12
+	b.utf8("Foo.java")                                 // 13
+	b.utf8("Lcom/acme/Nested;")                        // 14
+
+	b.u2(AccPublic | AccSuper)
+	b.u2(2) // this_class
+	b.u2(4) // super_class
+	b.u2(1) // interfaces
+	b.u2(6)
+
+	b.u2(0) // fields
+	b.u2(1) // methods
+	b.u2(AccPublic)
+	b.u2(11)
+	b.u2(11)
+	b.u2(1) // 方法属性
+	b.u2(12)
+	b.u4(3)
+	b.Write([]byte{1, 2, 3})
+
+	// 类属性
+	var annotations classBuilder
+	annotations.u2(1)
+	annotations.u2(10)
+	annotations.u2(2)
+	annotations.u2(11)
+	annotations.u1('s')
+	annotations.u2(13)
+	annotations.u2(11)
+	annotations.u1('[')
+	annotations.u2(2)
+	annotations.u1('@')
+	annotations.u2(14)
+	annotations.u2(0)
+	annotations.u1('e')
+	annotations.u2(14)
+	annotations.u2(11)
+
+	b.u2(2)
+	b.u2(9)
+	b.u4(uint32(annotations.Len()))
+	b.Write(annotations.Bytes())
+	b.u2(12)
+	b.u4(2)
+	b.u2(13)
+
+	return b.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	cf, err := Parse(bytes.NewReader(buildClass()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := &ClassFile{
+		MajorVersion: 52,
+		AccessFlags:  AccPublic | AccSuper,
+		ThisClass:    "com/acme/Foo",
+		SuperClass:   "java/lang/Object",
+		Interfaces:   []string{"java/io/Serializable"},
+		Annotations:  []string{"org/springframework/stereotype/Service"},
+		SourceFile:   "Foo.java",
+	}
+	if !reflect.DeepEqual(cf, expected) {
+		t.Errorf("Parse() = %+v, want %+v", cf, expected)
+	}
+	if cf.PackageName() != "com/acme" || cf.ClassName() != "com.acme.Foo" || cf.JavaVersion() != 8 || cf.Kind() != "class" {
+		t.Errorf("PackageName=%q ClassName=%q JavaVersion=%d Kind=%q", cf.PackageName(), cf.ClassName(), cf.JavaVersion(), cf.Kind())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空文件", nil},
+		{"文件头错误", []byte{0xCA, 0xFE, 0xD0, 0x0D, 0, 0, 0, 52}},
+		{"文件截断", buildClass()[:40]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(bytes.NewReader(tt.data)); err == nil {
+				t.Error("Parse() 应返回错误")
+			}
+		})
+	}
+}
+
+func TestJavaVersion(t *testing.T) {
+	tests := []struct {
+		major    uint16
+		expected int
+	}{
+		{45, 1},
+		{48, 1},
+		{49, 5},
+		{52, 8},
+		{61, 17},
+		{65, 21},
+		{10, 0},
+	}
+
+	for _, tt := range tests {
+		if result := JavaVersion(tt.major); result != tt.expected {
+			t.Errorf("JavaVersion(%d) = %d, want %d", tt.major, result, tt.expected)
+		}
+	}
+}
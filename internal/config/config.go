@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// FileName 项目配置文件名
+const FileName = ".emorad.yaml"
+
+// Settings 可写入配置文件的选项，键名与命令行参数一致，未设置的字段为 nil
+type Settings struct {
+	Workers          *int     `yaml:"workers"`
+	Include          []string `yaml:"include"`
+	Exclude          []string `yaml:"exclude"`
+	JarInclude       []string `yaml:"jar-include"`
+	SkipLibs         *bool    `yaml:"skip-libs"`
+	NoDefaultExclude *bool    `yaml:"no-default-exclude"`
+	CopyResources    *bool    `yaml:"copy-resources"`
+	CopyLibs         *bool    `yaml:"copy-libs"`
+	IdeaProject      *bool    `yaml:"idea-project"`
+	MavenProject     *bool    `yaml:"maven-project"`
+	GradleProject    *string  `yaml:"gradle-project"`
+	EclipseProject   *bool    `yaml:"eclipse-project"`
+	VSCodeProject    *bool    `yaml:"vscode-project"`
+	Layout           *string  `yaml:"layout"`
+	BatchSize        *int     `yaml:"batch-size"`
+	Daemon           *bool    `yaml:"daemon"`
+	Engine           *string  `yaml:"engine"`
+	FallbackEngine   *string  `yaml:"fallback-engine"`
+	ClassTimeout     *string  `yaml:"class-timeout"`
+	Timeout          *string  `yaml:"timeout"`
+	Mirror           []string `yaml:"mirror"`
+	MaxUnpackedSize  *string  `yaml:"max-unpacked-size"`
+	MaxEntries       *int     `yaml:"max-entries"`
+	MaxRatio         *float64 `yaml:"max-ratio"`
+	MaxDepth         *int     `yaml:"max-depth"`
+	Cache            *bool    `yaml:"cache"`
+	CacheDir         *string  `yaml:"cache-dir"`
+	FailOnErrorRate  *string  `yaml:"fail-on-error-rate"`
+
+	// CFR 传给 CFR 的选项，如 renamedupmembers: true
+	CFR map[string]string `yaml:"cfr"`
+}
+
+// Config 项目配置文件内容
+// 顶层选项对所有运行生效，profiles 中的命名方案在其基础上覆盖
+type Config struct {
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles"`
+
+	path string // 配置文件路径
+}
+
+// Path 返回配置文件路径
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Load 读取指定的配置文件
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("读取配置文件失败: %v"), err)
+	}
+
+	// 拒绝未知的键，避免拼写错误的选项被静默忽略
+	cfg := &Config{path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+		return nil, fmt.Errorf(i18n.T("解析配置文件失败 %s: %v"), path, err)
+	}
+	return cfg, nil
+}
+
+// Discover 查找并读取配置文件，未找到时返回 nil
+// 依次查找输入目录（输入为文件时取其所在目录）和 $HOME/.emorad/ 下的 .emorad.yaml
+func Discover(inputPath string) (*Config, error) {
+	for _, path := range candidates(inputPath) {
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+	}
+	return nil, nil
+}
+
+// candidates 返回按优先级排列的配置文件路径
+func candidates(inputPath string) []string {
+	dir := inputPath
+	if info, err := os.Stat(inputPath); err == nil && !info.IsDir() {
+		dir = filepath.Dir(inputPath)
+	}
+
+	paths := []string{filepath.Join(dir, FileName)}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(homeDir, ".emorad", FileName))
+	}
+	return paths
+}
+
+// Resolve 返回顶层选项与指定方案合并后的结果，profile 为空时只使用顶层选项
+func (c *Config) Resolve(profile string) (Settings, error) {
+	settings := c.Settings
+	if profile == "" {
+		return settings, nil
+	}
+
+	p, ok := c.Profiles[profile]
+	if !ok {
+		return settings, fmt.Errorf(i18n.T("配置文件 %s 中没有方案 %q，可用方案: %s"), c.path, profile, strings.Join(c.ProfileNames(), ", "))
+	}
+	settings.merge(p)
+	return settings, nil
+}
+
+// ProfileNames 返回排序后的方案名称
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge 用 other 中已设置的字段覆盖当前选项，CFR 选项按键合并
+func (s *Settings) merge(other Settings) {
+	if other.Workers != nil {
+		s.Workers = other.Workers
+	}
+	if other.Include != nil {
+		s.Include = other.Include
+	}
+	if other.Exclude != nil {
+		s.Exclude = other.Exclude
+	}
+	if other.JarInclude != nil {
+		s.JarInclude = other.JarInclude
+	}
+	if other.SkipLibs != nil {
+		s.SkipLibs = other.SkipLibs
+	}
+	if other.NoDefaultExclude != nil {
+		s.NoDefaultExclude = other.NoDefaultExclude
+	}
+	if other.CopyResources != nil {
+		s.CopyResources = other.CopyResources
+	}
+	if other.CopyLibs != nil {
+		s.CopyLibs = other.CopyLibs
+	}
+	if other.IdeaProject != nil {
+		s.IdeaProject = other.IdeaProject
+	}
+	if other.MavenProject != nil {
+		s.MavenProject = other.MavenProject
+	}
+	if other.GradleProject != nil {
+		s.GradleProject = other.GradleProject
+	}
+	if other.EclipseProject != nil {
+		s.EclipseProject = other.EclipseProject
+	}
+	if other.VSCodeProject != nil {
+		s.VSCodeProject = other.VSCodeProject
+	}
+	if other.Layout != nil {
+		s.Layout = other.Layout
+	}
+	if other.BatchSize != nil {
+		s.BatchSize = other.BatchSize
+	}
+	if other.Daemon != nil {
+		s.Daemon = other.Daemon
+	}
+	if other.Engine != nil {
+		s.Engine = other.Engine
+	}
+	if other.FallbackEngine != nil {
+		s.FallbackEngine = other.FallbackEngine
+	}
+	if other.ClassTimeout != nil {
+		s.ClassTimeout = other.ClassTimeout
+	}
+	if other.Timeout != nil {
+		s.Timeout = other.Timeout
+	}
+	if other.Mirror != nil {
+		s.Mirror = other.Mirror
+	}
+	if other.MaxUnpackedSize != nil {
+		s.MaxUnpackedSize = other.MaxUnpackedSize
+	}
+	if other.MaxEntries != nil {
+		s.MaxEntries = other.MaxEntries
+	}
+	if other.MaxRatio != nil {
+		s.MaxRatio = other.MaxRatio
+	}
+	if other.MaxDepth != nil {
+		s.MaxDepth = other.MaxDepth
+	}
+	if other.Cache != nil {
+		s.Cache = other.Cache
+	}
+	if other.CacheDir != nil {
+		s.CacheDir = other.CacheDir
+	}
+	if other.FailOnErrorRate != nil {
+		s.FailOnErrorRate = other.FailOnErrorRate
+	}
+	if len(other.CFR) > 0 {
+		cfr := make(map[string]string, len(s.CFR)+len(other.CFR))
+		for key, value := range s.CFR {
+			cfr[key] = value
+		}
+		for key, value := range other.CFR {
+			cfr[key] = value
+		}
+		s.CFR = cfr
+	}
+}
+
+// FlagValues 将已设置的选项转换为命令行参数值，键为参数名
+// 列表以逗号连接，与命令行中的写法一致；CFR 选项不在其中。
+// include、exclude 也不在其中：列表中的每一项是一条完整的规则，re: 正则可能包含逗号，连接后无法还原
+func (s *Settings) FlagValues() map[string]string {
+	values := make(map[string]string)
+	setInt := func(name string, v *int) {
+		if v != nil {
+			values[name] = strconv.Itoa(*v)
+		}
+	}
+	setBool := func(name string, v *bool) {
+		if v != nil {
+			values[name] = strconv.FormatBool(*v)
+		}
+	}
+	setString := func(name string, v *string) {
+		if v != nil {
+			values[name] = *v
+		}
+	}
+	setList := func(name string, v []string) {
+		if v != nil {
+			values[name] = strings.Join(v, ",")
+		}
+	}
+
+	setInt("workers", s.Workers)
+	setList("jar-include", s.JarInclude)
+	setBool("skip-libs", s.SkipLibs)
+	setBool("no-default-exclude", s.NoDefaultExclude)
+	setBool("copy-resources", s.CopyResources)
+	setBool("copy-libs", s.CopyLibs)
+	setBool("idea-project", s.IdeaProject)
+	setBool("maven-project", s.MavenProject)
+	setString("gradle-project", s.GradleProject)
+	setBool("eclipse-project", s.EclipseProject)
+	setBool("vscode-project", s.VSCodeProject)
+	setString("layout", s.Layout)
+	setInt("batch-size", s.BatchSize)
+	setBool("daemon", s.Daemon)
+	setString("engine", s.Engine)
+	setString("fallback-engine", s.FallbackEngine)
+	setString("class-timeout", s.ClassTimeout)
+	setString("timeout", s.Timeout)
+	setList("mirror", s.Mirror)
+	setString("max-unpacked-size", s.MaxUnpackedSize)
+	setInt("max-entries", s.MaxEntries)
+	if s.MaxRatio != nil {
+		values["max-ratio"] = strconv.FormatFloat(*s.MaxRatio, 'f', -1, 64)
+	}
+	setInt("max-depth", s.MaxDepth)
+	setBool("cache", s.Cache)
+	setString("cache-dir", s.CacheDir)
+	setString("fail-on-error-rate", s.FailOnErrorRate)
+	return values
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+
+	inputDir := t.TempDir()
+	jarPath := filepath.Join(inputDir, "app.jar")
+	if err := os.WriteFile(jarPath, []byte("jar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未找到配置文件
+	cfg, err := Discover(jarPath)
+	if err != nil || cfg != nil {
+		t.Fatalf("Discover() = %v, %v, want nil", cfg, err)
+	}
+
+	// 用户目录下的配置
+	if err := os.MkdirAll(filepath.Join(homeDir, ".emorad"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeConfig := filepath.Join(homeDir, ".emorad", FileName)
+	if err := os.WriteFile(homeConfig, []byte("cfr:\n  comments: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Discover(jarPath)
+	if err != nil || cfg == nil || cfg.Path() != homeConfig {
+		t.Fatalf("Discover() = %v, %v, want %s", cfg, err, homeConfig)
+	}
+
+	// 输入目录下的配置优先
+	inputConfig := filepath.Join(inputDir, FileName)
+	content := "cfr:\n  renamedupmembers: true\n  decodestringswitch: \"true\"\n"
+	if err := os.WriteFile(inputConfig, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Discover(jarPath)
+	if err != nil || cfg == nil || cfg.Path() != inputConfig {
+		t.Fatalf("Discover() = %v, %v, want %s", cfg, err, inputConfig)
+	}
+
+	expected := map[string]string{"renamedupmembers": "true", "decodestringswitch": "true"}
+	if !reflect.DeepEqual(cfg.CFR, expected) {
+		t.Errorf("CFR = %v, want %v", cfg.CFR, expected)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	content := `
+workers: 4
+include: [com.example]
+copy-resources: true
+cfr:
+  comments: false
+profiles:
+  core-only:
+    include: [com.example.core]
+    idea-project: true
+    cfr:
+      sugarenums: true
+`
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		flags   map[string]string
+		cfr     map[string]string
+		wantErr bool
+	}{
+		{"只使用顶层选项", "", map[string]string{"workers": "4", "copy-resources": "true"},
+			map[string]string{"comments": "false"}, false},
+		{"方案覆盖顶层选项", "core-only", map[string]string{"workers": "4", "copy-resources": "true", "idea-project": "true"},
+			map[string]string{"comments": "false", "sugarenums": "true"}, false},
+		{"未知方案", "missing", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := cfg.Resolve(tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if flags := settings.FlagValues(); !reflect.DeepEqual(flags, tt.flags) {
+				t.Errorf("FlagValues() = %v, want %v", flags, tt.flags)
+			}
+			if !reflect.DeepEqual(settings.CFR, tt.cfr) {
+				t.Errorf("CFR = %v, want %v", settings.CFR, tt.cfr)
+			}
+		})
+	}
+
+	// 顶层 CFR 选项不应被方案修改
+	if len(cfg.CFR) != 1 {
+		t.Errorf("顶层 CFR 选项被修改: %v", cfg.CFR)
+	}
+}
+
+func TestLoadUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("incldue: [com.example]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() 应拒绝未知的配置项")
+	}
+}
@@ -0,0 +1,100 @@
+// Package console 输出带颜色的控制台消息
+//
+// 一次反编译的所有控制台输出都通过同一个 Console，嵌入到其他程序时可以写入独立的
+// io.Writer 并使用独立的语言，不修改 fatih/color 和 i18n 的全局设置。
+// nil 的 *Console 写入 color.Output，颜色和语言跟随全局设置，与命令行的行为相同。
+package console
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// Console 控制台输出
+type Console struct {
+	w       io.Writer
+	colored bool
+	lang    string
+	onWarn  func(message string) // Warn 的回调，nil 表示只输出
+}
+
+// New 创建写入 w 的控制台，colored 为 false 时不输出 ANSI 颜色，lang 为空时使用 i18n 的当前语言
+func New(w io.Writer, colored bool, lang string) *Console {
+	return &Console{w: w, colored: colored, lang: lang}
+}
+
+// Writer 返回输出目标
+func (c *Console) Writer() io.Writer {
+	if c == nil || c.w == nil {
+		return color.Output
+	}
+	return c.w
+}
+
+// Lang 返回消息使用的语言
+func (c *Console) Lang() string {
+	if c == nil || c.lang == "" {
+		return i18n.Lang()
+	}
+	return c.lang
+}
+
+// T 返回消息在控制台语言下的文本
+func (c *Console) T(msg string) string {
+	return i18n.In(c.Lang(), msg)
+}
+
+// Green 输出绿色的消息，末尾没有换行时自动添加，与 color.Green 相同
+func (c *Console) Green(format string, a ...interface{}) { c.print(color.FgGreen, format, a...) }
+
+// Yellow 输出黄色的消息
+func (c *Console) Yellow(format string, a ...interface{}) { c.print(color.FgYellow, format, a...) }
+
+// Red 输出红色的消息
+func (c *Console) Red(format string, a ...interface{}) { c.print(color.FgRed, format, a...) }
+
+// Cyan 输出青色的消息
+func (c *Console) Cyan(format string, a ...interface{}) { c.print(color.FgCyan, format, a...) }
+
+// Warn 以黄色输出 [WARN] 警告，并将去掉前缀的消息传给 WithWarningHandler 设置的回调
+func (c *Console) Warn(format string, a ...interface{}) {
+	c.print(color.FgYellow, format, a...)
+	if c != nil && c.onWarn != nil {
+		message := strings.TrimSpace(fmt.Sprintf(format, a...))
+		c.onWarn(strings.TrimSpace(strings.TrimPrefix(message, "[WARN]")))
+	}
+}
+
+// WithWarningHandler 返回输出相同、Warn 时调用 fn 的控制台，不修改 c
+func (c *Console) WithWarningHandler(fn func(message string)) *Console {
+	copied := &Console{}
+	if c != nil {
+		*copied = *c
+	}
+	copied.onWarn = fn
+	return copied
+}
+
+// Printf 输出不带颜色的消息
+func (c *Console) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(c.Writer(), format, a...)
+}
+
+func (c *Console) print(attr color.Attribute, format string, a ...interface{}) {
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	col := color.New(attr)
+	if c != nil && c.w != nil {
+		if c.colored {
+			col.EnableColor()
+		} else {
+			col.DisableColor()
+		}
+	}
+	fmt.Fprint(c.Writer(), col.Sprintf(format, a...))
+}
@@ -0,0 +1,50 @@
+package console
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+func TestConsole(t *testing.T) {
+	tests := []struct {
+		name    string
+		colored bool
+		lang    string
+		want    string
+	}{
+		{"无颜色中文", false, i18n.Chinese, "成功: 3\n"},
+		{"无颜色英文", false, i18n.English, "Succeeded: 3\n"},
+		{"带颜色", true, i18n.Chinese, "\x1b[32m成功: 3\n\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := New(&buf, tt.colored, tt.lang)
+			c.Green(c.T("成功")+": %d", 3)
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWarningHandler(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(&buf, false, i18n.Chinese)
+	var messages []string
+	c := base.WithWarningHandler(func(message string) { messages = append(messages, message) })
+
+	c.Warn("\n[WARN] 无法使用缓存: %v", "只读")
+	base.Warn("[WARN] 未设置回调")
+	if len(messages) != 1 || messages[0] != "无法使用缓存: 只读" {
+		t.Errorf("messages = %q", messages)
+	}
+	if want := "\n[WARN] 无法使用缓存: 只读\n[WARN] 未设置回调\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	var nilConsole *Console
+	nilConsole.WithWarningHandler(func(string) {}).Warn("[WARN] nil")
+}
@@ -1,145 +1,441 @@
 package decompile
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/fatih/color"
-	"github.com/jiaozhu/emorad/internal/cfr"
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+	"github.com/jiaozhu/emorad/internal/engine"
 	"github.com/jiaozhu/emorad/internal/processor"
 	"github.com/jiaozhu/emorad/internal/report"
 )
 
-// Run 执行反编译操作
-func Run(inputPath, outputDir string, workers int, filterConfig *processor.FilterConfig) error {
+// Output 一次运行的消息和进度事件的去向，由调用方提供
+type Output struct {
+	Console   *console.Console   // 控制台输出和消息语言，为 nil 时写入 color.Output 并使用全局语言
+	Events    io.Writer          // 非空时以 NDJSON 格式输出进度事件，不再显示进度行
+	OnEvent   func(report.Event) // 非空时对每个进度事件调用，供嵌入使用
+	InputName string             // 报告中显示的输入，为空时使用实际的输入路径
+}
+
+// Run 执行反编译操作，返回的报告在开始处理之前出错时为 nil
+// filterConfig 在运行中不会被修改；输出目录和缓存等运行时确定的内容保存在 processor.RunContext 中
+// ctx 取消（超时或中断）时终止所有反编译进程，并为已完成的部分生成报告
+func Run(ctx context.Context, inputPath, outputDir string, workers int, filterConfig *processor.FilterConfig, output Output) (*report.Report, error) {
+	out := output.Console
+	out.Cyan(out.T("\n[START] 开始反编译..."))
+	out.Cyan("============================================")
+
+	if err := filterConfig.Validate(); err != nil {
+		out.Red("[ERROR] %v", err)
+		return nil, exitError(ExitInput, err)
+	}
+	if err := validateProject(filterConfig, out); err != nil {
+		out.Red("[ERROR] %v", err)
+		return nil, exitError(ExitInput, err)
+	}
+
+	// 先检查输入路径，避免在初始化反编译器之后才发现输入无效
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		out.Red(out.T("[ERROR] 无法访问输入路径: %v"), err)
+		return nil, exitError(ExitInput, err)
+	}
+	ext := strings.ToLower(filepath.Ext(inputPath))
+	if !info.IsDir() && ext != ".jar" && ext != ".war" && ext != ".class" {
+		out.Red(out.T("[ERROR] 不支持的文件类型: %s"), ext)
+		return nil, exitError(ExitInput, fmt.Errorf(out.T("不支持的文件类型: %s"), ext))
+	}
 
-	color.Cyan("\n[START] 开始反编译...")
-	color.Cyan("============================================")
+	// 创建报告，报告和运行日志位于输出根目录；此后的 [WARN] 消息同时输出为 warning 事件
+	rpt := report.New(inputPath, outputDir)
+	if output.InputName != "" {
+		rpt.InputPath = output.InputName
+	}
+	out = out.WithWarningHandler(rpt.Warn)
+	rpt.SetConsole(out)
+	if output.Events != nil {
+		rpt.SetEventOutput(output.Events)
+	}
+	if output.OnEvent != nil {
+		rpt.SetEventHandler(output.OnEvent)
+	}
 
 	// 显示过滤配置
 	if len(filterConfig.Includes) > 0 {
-		color.Green("[FILTER] 包含过滤: %v", filterConfig.Includes)
+		out.Green(out.T("[FILTER] 包含过滤: %v"), filterConfig.Includes)
 	}
 	if len(filterConfig.Excludes) > 0 {
-		color.Yellow("[FILTER] 排除过滤: %d 个包前缀", len(filterConfig.Excludes))
+		out.Yellow(out.T("[FILTER] 排除过滤: %d 条规则"), len(filterConfig.Excludes))
 	}
 	if filterConfig.SkipLibs {
-		color.Yellow("[CONFIG] 跳过依赖库: 已启用")
+		out.Yellow(out.T("[CONFIG] 跳过依赖库: 已启用"))
 	}
 	if len(filterConfig.JarIncludes) > 0 {
-		color.Green("[FILTER] JAR 名称过滤: %v", filterConfig.JarIncludes)
+		out.Green(out.T("[FILTER] JAR 名称过滤: %v"), filterConfig.JarIncludes)
 	}
 	if filterConfig.CopyResources {
-		color.Green("[CONFIG] 复制配置文件: 已启用")
+		out.Green(out.T("[CONFIG] 复制配置文件: 已启用"))
 	}
 	if filterConfig.CopyLibJars {
-		color.Green("[CONFIG] 复制依赖 JAR: 已启用")
+		out.Green(out.T("[CONFIG] 复制依赖 JAR: 已启用"))
 	}
 	if filterConfig.GenerateIDEA {
-		color.Green("[CONFIG] 生成 IDEA 项目: 已启用")
+		out.Green(out.T("[CONFIG] 生成 IDEA 项目: 已启用"))
+	}
+	if filterConfig.GenerateMaven {
+		out.Green(out.T("[CONFIG] 生成 Maven 项目: 已启用"))
+	}
+	if filterConfig.GenerateGradle != "" {
+		out.Green(out.T("[CONFIG] 生成 Gradle 项目: %s DSL"), filterConfig.GenerateGradle)
+	}
+	if filterConfig.GenerateEclipse {
+		out.Green(out.T("[CONFIG] 生成 Eclipse 项目: 已启用"))
+	}
+	if filterConfig.GenerateVSCode {
+		out.Green(out.T("[CONFIG] 生成 VS Code 项目: 已启用"))
+	}
+	if filterConfig.Layout == processor.LayoutMaven {
+		out.Green(out.T("[CONFIG] 目录结构: src/main/java、src/main/resources、src/main/webapp"))
+	}
+	if filterConfig.Engine != "" && filterConfig.Engine != engine.DefaultEngine {
+		out.Green(out.T("[CONFIG] 反编译引擎: %s"), filterConfig.Engine)
+	}
+	if filterConfig.FallbackEngine != "" {
+		out.Green(out.T("[CONFIG] 备用反编译引擎: %s"), filterConfig.FallbackEngine)
+	}
+	if filterConfig.ClassTimeout > 0 {
+		out.Green(out.T("[CONFIG] 单个 class 超时: %s"), filterConfig.ClassTimeout)
+	}
+	if filterConfig.BatchSize > 0 {
+		out.Green(out.T("[CONFIG] 批量反编译: 每个进程 %d 个 class"), filterConfig.BatchSize)
+	}
+	if filterConfig.UseDaemon {
+		out.Green(out.T("[CONFIG] 常驻反编译进程: 已启用"))
+	}
+	if len(filterConfig.CFROptions) > 0 {
+		out.Green(out.T("[CONFIG] CFR 选项: %v"), filterConfig.CFROptions)
 	}
 
-	// 初始化CFR管理器
-	color.Cyan("[INIT] 初始化反编译器...")
-	cfrManager, err := cfr.NewManager()
+	// 初始化反编译引擎
+	out.Cyan(out.T("[INIT] 初始化反编译器..."))
+	downloadOpts := download.DefaultOptions()
+	downloadOpts.Mirrors = append(append([]string{}, filterConfig.Mirrors...), downloadOpts.Mirrors...)
+	if output.Events == nil {
+		downloadOpts.Progress = out.Writer()
+	}
+	engineOpts := engine.Options{
+		CFRJar:     filterConfig.CFRJar,
+		CFROptions: filterConfig.CFROptions,
+		Download:   downloadOpts,
+		Console:    out,
+	}
+	decompiler, err := engine.New(filterConfig.Engine, engineOpts)
 	if err != nil {
-		color.Red("[ERROR] 初始化CFR失败: %v", err)
-		color.Yellow("\n[TIP] 提示:")
-		color.Yellow("   1. 请确保已安装Java环境")
-		color.Yellow("   2. 工具会自动下载CFR反编译器")
-		color.Yellow("   3. 或手动安装: brew install cfr-decompiler")
-		return err
+		out.Red(out.T("[ERROR] 初始化反编译引擎失败: %v"), err)
+		out.Yellow(out.T("\n[TIP] 提示:"))
+		out.Yellow(out.T("   1. 请确保已安装Java环境"))
+		out.Yellow(out.T("   2. 工具会自动下载反编译器 JAR"))
+		out.Yellow(out.T("   3. 离线环境可使用 emorad engine install --from <file> 安装"))
+		out.Yellow(out.T("   4. 或手动安装: brew install cfr-decompiler"))
+		return nil, exitError(ExitSetup, err)
 	}
 
-	// 创建输出目录（源代码放在 src 子目录）
-	srcDir := outputDir
-	if filterConfig.GenerateIDEA {
-		srcDir = filepath.Join(outputDir, "src")
+	// 启动常驻进程池，失败时回退到每个 class 一个进程
+	if filterConfig.UseDaemon {
+		if daemon, ok := decompiler.(engine.DaemonDecompiler); ok {
+			if err := daemon.StartDaemon(workers); err != nil {
+				out.Warn(out.T("[WARN] 常驻进程模式不可用，使用独立进程: %v"), err)
+			}
+			defer daemon.Close()
+		} else {
+			out.Warn(out.T("[WARN] %s 引擎不支持常驻进程模式，使用独立进程"), decompiler.Name())
+		}
 	}
-	if err := os.MkdirAll(srcDir, 0755); err != nil {
-		color.Red("[ERROR] 创建输出目录失败: %v", err)
-		return err
+
+	// 配置备用引擎，主引擎失败或输出含失败标记时重试
+	if filterConfig.FallbackEngine != "" {
+		fallback, err := engine.New(filterConfig.FallbackEngine, engineOpts)
+		if err != nil {
+			out.Warn(out.T("[WARN] 初始化备用引擎失败，不启用重试: %v"), err)
+		} else {
+			decompiler = engine.NewFallback(decompiler, fallback)
+		}
 	}
 
-	// 检查输入路径的类型
-	info, err := os.Stat(inputPath)
-	if err != nil {
-		color.Red("[ERROR] 无法访问输入路径: %v", err)
-		return err
+	var run processor.RunContext
+
+	// 打开反编译结果缓存，键中包含引擎的版本、JAR 摘要和选项，更换引擎 JAR 或配置后不会使用旧结果
+	if filterConfig.CacheDir != "" {
+		salt := fmt.Sprintf("engine=%s cfr=%s", engine.Identity(decompiler), formatOptions(filterConfig.CFROptions))
+		c, err := cache.New(filterConfig.CacheDir, salt)
+		if err != nil {
+			out.Warn(out.T("[WARN] 无法使用缓存: %v"), err)
+		} else {
+			run.Cache = c
+			out.Green(out.T("[CONFIG] 反编译缓存: %s"), c.Dir())
+		}
 	}
 
-	// 创建报告
-	rpt := report.New(inputPath, srcDir)
+	// 确定输出目录结构：生成项目时依赖 JAR 放在 libs 目录，flat 结构的源代码放在 src 子目录、
+	// 配置文件放在与其并列的 resources 目录；maven 结构使用 src/main/java、src/main/resources、
+	// src/main/webapp，Gradle 项目总是使用 maven 结构
+	layout := filterConfig.Layout
+	if filterConfig.GenerateGradle != "" {
+		if layout == processor.LayoutFlat {
+			out.Warn(out.T("[WARN] Gradle 项目使用 maven 目录结构，忽略 --layout flat"))
+		}
+		layout = processor.LayoutMaven
+	}
+	generateProject := filterConfig.GenerateIDEA || filterConfig.GenerateMaven || filterConfig.GenerateGradle != "" ||
+		filterConfig.GenerateEclipse || filterConfig.GenerateVSCode
+	project := &processor.ProjectConfig{
+		InputPath:    inputPath,
+		OutputDir:    outputDir,
+		SrcDir:       outputDir,
+		ResourcesDir: filepath.Join(outputDir, "resources"),
+		LibsDir:      filepath.Join(outputDir, "libs"),
+	}
+	switch {
+	case layout == processor.LayoutMaven:
+		project.SrcDir = filepath.Join(outputDir, "src", "main", "java")
+		project.ResourcesDir = filepath.Join(outputDir, "src", "main", "resources")
+		project.WebappDir = filepath.Join(outputDir, "src", "main", "webapp")
+	case generateProject:
+		project.SrcDir = filepath.Join(outputDir, "src")
+	}
+	srcDir := project.SrcDir
+	if filterConfig.CopyResources {
+		run.ResourcesDir = project.ResourcesDir
+	}
+	// 构建文件以本地文件引用无法解析坐标的依赖，Eclipse、VS Code 直接引用 libs 中的 JAR
+	if filterConfig.CopyLibJars || filterConfig.GenerateMaven || filterConfig.GenerateGradle != "" ||
+		filterConfig.GenerateEclipse || filterConfig.GenerateVSCode {
+		run.LibsDir = project.LibsDir
+	}
+	run.WebappDir = project.WebappDir
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		out.Red(out.T("[ERROR] 创建输出目录失败: %v"), err)
+		return nil, exitError(ExitSetup, err)
+	}
 
 	// 根据文件类型选择处理器
 	var proc processor.Processor
 
 	if info.IsDir() {
 		// 目录处理
-		proc = processor.NewDirectoryProcessor(cfrManager, workers, filterConfig)
-		color.Cyan("[DETECT] 检测到目录,使用目录处理器")
+		proc = processor.NewDirectoryProcessor(decompiler, workers, filterConfig, run)
+		out.Cyan(out.T("[DETECT] 检测到目录,使用目录处理器"))
 	} else {
 		// 文件处理
-		ext := strings.ToLower(filepath.Ext(inputPath))
 		switch ext {
 		case ".jar":
-			proc = processor.NewJarProcessor(cfrManager, workers, filterConfig)
-			color.Cyan("[DETECT] 检测到JAR文件,使用JAR处理器")
+			proc = processor.NewJarProcessor(decompiler, workers, filterConfig, run)
+			out.Cyan(out.T("[DETECT] 检测到JAR文件,使用JAR处理器"))
 		case ".war":
-			proc = processor.NewWarProcessor(cfrManager, workers, filterConfig)
-			color.Cyan("[DETECT] 检测到WAR文件,使用WAR处理器")
+			proc = processor.NewWarProcessor(decompiler, workers, filterConfig, run)
+			out.Cyan(out.T("[DETECT] 检测到WAR文件,使用WAR处理器"))
 		case ".class":
-			proc = processor.NewClassProcessor(cfrManager)
-			color.Cyan("[DETECT] 检测到CLASS文件,使用CLASS处理器")
+			proc = processor.NewClassProcessor(decompiler, filterConfig.ClassTimeout).WithCache(run.Cache)
+			out.Cyan(out.T("[DETECT] 检测到CLASS文件,使用CLASS处理器"))
 			rpt.SetTotalExpectedFiles(1)
 		default:
-			return fmt.Errorf("不支持的文件类型: %s", ext)
+			return rpt, exitError(ExitInput, fmt.Errorf(out.T("不支持的文件类型: %s"), ext))
+		}
+	}
+
+	// 记录运行日志，中断后可使用 --resume 跳过已完成的部分
+	if proc.GetType() != "class" {
+		resumed, err := rpt.OpenJournal(filterConfig.Resume)
+		if err != nil {
+			out.Red("[ERROR] %v", err)
+			return rpt, exitError(ExitInput, err)
+		}
+		if resumed > 0 {
+			out.Green(out.T("[RESUME] 已合并上次运行的 %d 个结果"), resumed)
+		} else if filterConfig.Resume {
+			out.Yellow(out.T("[RESUME] 未找到可恢复的运行记录，从头开始"))
 		}
+	} else if filterConfig.Resume {
+		out.Warn(out.T("[WARN] 单个 class 文件无需恢复，忽略 --resume"))
 	}
 
-	color.Cyan("============================================\n")
+	out.Cyan("============================================\n")
 
 	// 执行处理
-	if err := proc.Process(inputPath, srcDir, rpt); err != nil {
-		color.Red("\n[ERROR] 处理失败: %v", err)
-		// 即使有错误也生成报告
+	if err := proc.Process(ctx, inputPath, srcDir, rpt); err != nil {
+		code := ExitInput
+		if ctx.Err() != nil {
+			out.Warn(out.T("\n[WARN] 反编译已中断: %v，生成部分报告"), ctx.Err())
+			rpt.Interrupted = true
+			code = ExitPartial
+		} else {
+			out.Red(out.T("\n[ERROR] 处理失败: %v"), err)
+			if rpt.SuccessCount == 0 && rpt.FailureCount > 0 {
+				code = ExitTotal
+			}
+		}
+		// 即使有错误也生成报告，保留运行日志以便恢复
+		rpt.CloseJournal(false)
 		rpt.Generate()
-		return err
+		return rpt, exitError(code, err)
 	}
+	rpt.CloseJournal(true)
 
 	// Unicode 后处理：将 \uXXXX 转换为实际的中文字符
-	color.Cyan("\n[PROCESS] 处理 Unicode 转义序列...")
+	out.Cyan(out.T("\n[PROCESS] 处理 Unicode 转义序列..."))
 	processed, modified, err := processor.ProcessDirectoryUnicode(srcDir)
 	if err != nil {
-		color.Yellow("[WARN] Unicode 后处理警告: %v", err)
+		out.Warn(out.T("[WARN] Unicode 后处理警告: %v"), err)
 	} else if modified > 0 {
-		color.Green("[OK] Unicode 后处理完成: 处理 %d 文件, 修复 %d 文件", processed, modified)
+		out.Green(out.T("[OK] Unicode 后处理完成: 处理 %d 文件, 修复 %d 文件"), processed, modified)
+	}
+
+	if generateProject {
+		generateProjectFiles(project, filterConfig, rpt)
+	}
+
+	// 生成报告
+	if err := rpt.Generate(); err != nil {
+		return rpt, err
+	}
+	return rpt, checkResults(rpt, filterConfig.MaxErrorRate)
+}
+
+// generateProjectFiles 生成 IDEA、Eclipse、VS Code、Maven、Gradle 项目配置，失败时只输出警告
+// projectConfig 中已设置输入和各目录；项目的 Java 版本取自反编译结果中的 class 版本，没有时取自输入的 MANIFEST.MF
+func generateProjectFiles(projectConfig *processor.ProjectConfig, filterConfig *processor.FilterConfig, rpt *report.Report) {
+	out := rpt.Console()
+	outputDir := projectConfig.OutputDir
+	projectConfig.ProjectName = filepath.Base(outputDir)
+	if projectConfig.ProjectName == "." || projectConfig.ProjectName == "" {
+		projectConfig.ProjectName = "decompiled"
+	}
+
+	javaVersion, source := processor.DetectJavaVersion(projectConfig.InputPath, rpt.ApplicationJavaVersionHistogram())
+	projectConfig.JavaVersion = javaVersion
+	switch source {
+	case processor.JavaVersionFromClass:
+		out.Cyan(out.T("[JDK] 项目 Java 版本: %d（应用 class 文件最多的编译目标）"), javaVersion)
+	case "":
+		out.Yellow(out.T("[JDK] 无法确定 Java 版本，使用 %d"), javaVersion)
+	default:
+		out.Cyan(out.T("[JDK] 项目 Java 版本: %d（MANIFEST.MF 的 %s）"), javaVersion, source)
+	}
+	// 没有 web 内容时不生成 webapp 相关配置
+	if info, err := os.Stat(projectConfig.WebappDir); projectConfig.WebappDir == "" || err != nil || !info.IsDir() {
+		projectConfig.WebappDir = ""
 	}
 
-	// 生成 IDEA 项目配置
 	if filterConfig.GenerateIDEA {
-		color.Cyan("\n[PROCESS] 生成 IDEA 项目配置...")
-		projectName := filepath.Base(outputDir)
-		if projectName == "." || projectName == "" {
-			projectName = "decompiled"
+		out.Cyan(out.T("\n[PROCESS] 生成 IDEA 项目配置..."))
+		if err := processor.GenerateIDEAProject(projectConfig); err != nil {
+			out.Warn(out.T("[WARN] 生成 IDEA 项目配置失败: %v"), err)
+		} else {
+			out.Green(out.T("[OK] IDEA 项目配置已生成，可直接用 IDEA 打开: %s"), outputDir)
 		}
+	}
 
-		projectConfig := &processor.ProjectConfig{
-			ProjectName: projectName,
-			OutputDir:   outputDir,
-			SrcDir:      srcDir,
-			LibsDir:     filepath.Join(outputDir, "libs"),
+	if filterConfig.GenerateEclipse {
+		out.Cyan(out.T("\n[PROCESS] 生成 Eclipse 项目配置..."))
+		if err := processor.GenerateEclipseProject(projectConfig); err != nil {
+			out.Warn(out.T("[WARN] 生成 Eclipse 项目配置失败: %v"), err)
+		} else {
+			out.Green(out.T("[OK] Eclipse 项目配置已生成，可通过 File > Import > Existing Projects 导入: %s"), outputDir)
 		}
+	}
 
-		if err := processor.GenerateIDEAProject(projectConfig); err != nil {
-			color.Yellow("[WARN] 生成 IDEA 项目配置失败: %v", err)
+	if filterConfig.GenerateVSCode {
+		out.Cyan(out.T("\n[PROCESS] 生成 VS Code 项目配置..."))
+		if err := processor.GenerateVSCodeProject(projectConfig); err != nil {
+			out.Warn(out.T("[WARN] 生成 VS Code 项目配置失败: %v"), err)
 		} else {
-			color.Green("[OK] IDEA 项目配置已生成，可直接用 IDEA 打开: %s", outputDir)
+			out.Green(out.T("[OK] VS Code 项目配置已生成: %s"), filepath.Join(outputDir, ".vscode", "settings.json"))
 		}
 	}
 
-	// 生成报告
-	return rpt.Generate()
+	if filterConfig.GenerateMaven {
+		out.Cyan(out.T("\n[PROCESS] 生成 Maven pom.xml..."))
+		deps, err := processor.GenerateMavenProject(projectConfig)
+		if err != nil {
+			out.Warn(out.T("[WARN] 生成 Maven pom.xml 失败: %v"), err)
+		} else {
+			out.Green(out.T("[OK] pom.xml 已生成: %d 个依赖，其中 %d 个使用 Maven 坐标"), len(deps), resolvedCount(deps))
+			warnUnresolved(out, deps)
+		}
+	}
+
+	if filterConfig.GenerateGradle != "" {
+		out.Cyan(out.T("\n[PROCESS] 生成 Gradle 构建文件..."))
+		deps, err := processor.GenerateGradleProject(projectConfig, filterConfig.GenerateGradle)
+		if err != nil {
+			out.Warn(out.T("[WARN] 生成 Gradle 构建文件失败: %v"), err)
+		} else {
+			out.Green(out.T("[OK] Gradle 构建文件已生成: %d 个依赖，其中 %d 个使用 Maven 坐标"), len(deps), resolvedCount(deps))
+			if !filterConfig.GenerateMaven {
+				warnUnresolved(out, deps)
+			}
+		}
+	}
+}
+
+// validateProject 检查目录结构和 Gradle DSL 的取值
+func validateProject(filterConfig *processor.FilterConfig, out *console.Console) error {
+	switch filterConfig.Layout {
+	case "", processor.LayoutFlat, processor.LayoutMaven:
+	default:
+		return fmt.Errorf(out.T("无效的目录结构 %q，可选 flat、maven"), filterConfig.Layout)
+	}
+	switch filterConfig.GenerateGradle {
+	case "", processor.GradleGroovy, processor.GradleKotlin:
+	default:
+		return fmt.Errorf(out.T("无效的 Gradle DSL %q，可选 groovy、kotlin"), filterConfig.GenerateGradle)
+	}
+	return nil
+}
+
+// resolvedCount 返回找到 Maven 坐标的依赖数量
+func resolvedCount(deps []processor.Dependency) int {
+	count := 0
+	for _, dep := range deps {
+		if dep.Resolved {
+			count++
+		}
+	}
+	return count
+}
+
+// warnUnresolved 列出未找到 Maven 坐标、只能以本地文件引用的依赖
+func warnUnresolved(out *console.Console, deps []processor.Dependency) {
+	var unresolved []string
+	for _, dep := range deps {
+		if !dep.Resolved {
+			unresolved = append(unresolved, dep.File)
+		}
+	}
+	if len(unresolved) > 0 {
+		out.Warn(out.T("[WARN] 以下依赖未找到 Maven 坐标，以本地文件引用 libs 目录: %s"), strings.Join(unresolved, ", "))
+	}
+}
+
+// formatOptions 按键名排序输出选项，保证相同选项得到相同的缓存键
+func formatOptions(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + options[key]
+	}
+	return strings.Join(parts, ",")
 }
@@ -0,0 +1,71 @@
+package decompile
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/processor"
+)
+
+// buildZip 生成包含给定条目的压缩包
+func buildZip(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range entries {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRunKeepsFilterConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 shell 脚本模拟 java")
+	}
+	bin := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "java"), []byte("#!/bin/sh\nexit 0\n"), 0755)
+	t.Setenv("PATH", bin)
+
+	input := filepath.Join(t.TempDir(), "app.jar")
+	os.WriteFile(input, buildZip(t, map[string][]byte{
+		"BOOT-INF/classes/application.yml": []byte("server:\n  port: 8080\n"),
+		"BOOT-INF/lib/dep.jar":             buildZip(t, nil),
+	}), 0644)
+
+	filterConfig := processor.NewDefaultFilterConfig()
+	filterConfig.CFRJar = filepath.Join(bin, "cfr.jar")
+	os.WriteFile(filterConfig.CFRJar, buildZip(t, nil), 0644)
+	filterConfig.CopyResources = true
+	filterConfig.Layout = processor.LayoutFlat
+	filterConfig.GenerateGradle = processor.GradleGroovy
+
+	outputDir := t.TempDir()
+	output := Output{Console: console.New(io.Discard, false, "")}
+	if _, err := Run(context.Background(), input, outputDir, 1, filterConfig, output); err != nil {
+		t.Fatal(err)
+	}
+
+	// Gradle 项目使用 maven 结构并复制依赖 JAR，但不修改调用方的配置
+	for _, name := range []string{"src/main/resources/application.yml", "libs/dep.jar", "build.gradle"} {
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if filterConfig.Layout != processor.LayoutFlat || filterConfig.CopyLibJars {
+		t.Errorf("Layout = %q, CopyLibJars = %v, want flat and false", filterConfig.Layout, filterConfig.CopyLibJars)
+	}
+}
@@ -0,0 +1,70 @@
+package decompile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// 进程退出码，供 CI 根据反编译结果决定是否继续
+const (
+	ExitOK      = 0 // 全部成功，或失败率未超过阈值
+	ExitGeneric = 1 // 其他错误，如命令行参数错误
+	ExitInput   = 2 // 输入错误：路径不存在、文件类型不支持、配置或过滤规则无效
+	ExitSetup   = 3 // 环境错误：缺少 Java、无法获取反编译器、无法创建输出目录
+	ExitPartial = 4 // 部分失败：失败率超过 --fail-on-error-rate，或运行被中断
+	ExitTotal   = 5 // 全部失败：没有任何源文件反编译成功
+)
+
+// ExitError 带有退出码的错误
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitError 为错误附加退出码
+func exitError(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode 返回错误对应的退出码，nil 为 ExitOK，未附加退出码的错误为 ExitGeneric
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitGeneric
+}
+
+// checkResults 根据报告中的成功和失败数量判断本次运行是否合格
+// maxErrorRate 为允许的最大失败率（0-1），全部失败时无论阈值都返回 ExitTotal
+func checkResults(rpt *report.Report, maxErrorRate float64) error {
+	success, failure := rpt.SuccessCount, rpt.FailureCount
+	total := success + failure
+	if failure == 0 {
+		return nil
+	}
+	if success == 0 {
+		return exitError(ExitTotal, fmt.Errorf(i18n.T("全部 %d 个源文件反编译失败"), failure))
+	}
+
+	rate := float64(failure) / float64(total)
+	if rate > maxErrorRate {
+		return exitError(ExitPartial, fmt.Errorf(i18n.T("失败率 %.2f%% (%d/%d) 超过阈值 %.2f%%"),
+			rate*100, failure, total, maxErrorRate*100))
+	}
+	return nil
+}
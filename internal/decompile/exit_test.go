@@ -0,0 +1,43 @@
+package decompile
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+func TestCheckResults(t *testing.T) {
+	tests := []struct {
+		name         string
+		success      int32
+		failure      int32
+		maxErrorRate float64
+		want         int
+	}{
+		{"全部成功", 10, 0, 0, ExitOK},
+		{"没有处理任何文件", 0, 0, 0, ExitOK},
+		{"默认不允许失败", 99, 1, 0, ExitPartial},
+		{"未超过阈值", 95, 5, 0.05, ExitOK},
+		{"超过阈值", 94, 6, 0.05, ExitPartial},
+		{"全部失败", 0, 3, 1, ExitTotal},
+	}
+
+	for _, tt := range tests {
+		rpt := &report.Report{SuccessCount: tt.success, FailureCount: tt.failure}
+		if got := ExitCode(checkResults(rpt, tt.maxErrorRate)); got != tt.want {
+			t.Errorf("%s: exit code = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	wrapped := fmt.Errorf("运行失败: %w", exitError(ExitSetup, errors.New("未找到 Java")))
+	if got := ExitCode(wrapped); got != ExitSetup {
+		t.Errorf("ExitCode(wrapped) = %d, want %d", got, ExitSetup)
+	}
+	if got := ExitCode(errors.New("unknown flag")); got != ExitGeneric {
+		t.Errorf("ExitCode(plain) = %d, want %d", got, ExitGeneric)
+	}
+}
@@ -0,0 +1,337 @@
+package download
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// MavenCentral Maven 中央仓库地址，作为最后的下载来源
+const MavenCentral = "https://repo1.maven.org/maven2"
+
+// MirrorsEnv 配置下载镜像的环境变量，多个地址以逗号分隔
+const MirrorsEnv = "EMORAD_MIRRORS"
+
+// Artifact 要下载的文件
+type Artifact struct {
+	URL    string // 官方下载地址
+	Maven  string // Maven 坐标 group:artifact:version[:classifier]，为空时不尝试 Maven 仓库
+	SHA256 string // 期望的 SHA-256，为空时拒绝下载
+}
+
+// Options 下载配置
+type Options struct {
+	// Mirrors 优先尝试的镜像，可以是 Maven 仓库根地址（如 Nexus/Artifactory），
+	// 也可以是包含 {file} 占位符的地址模板（如 https://mirror.example.com/jars/{file}）
+	Mirrors  []string
+	Retries  int           // 每个地址失败后的重试次数
+	Backoff  time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	Progress io.Writer     // 下载进度输出，为 nil 时不显示
+	Client   *http.Client  // 为 nil 时使用支持 HTTP(S)_PROXY 的默认客户端
+}
+
+// DefaultOptions 返回默认下载配置，镜像来自环境变量 EMORAD_MIRRORS
+func DefaultOptions() Options {
+	return Options{
+		Mirrors: SplitList(os.Getenv(MirrorsEnv)),
+		Retries: 2,
+		Backoff: time.Second,
+	}
+}
+
+// SplitList 拆分逗号分隔的列表并去掉空白项
+func SplitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Fetch 下载 artifact 到 destPath
+// 依次尝试镜像、官方地址和 Maven 中央仓库，每个地址失败后按指数退避重试。
+// 内容先写入同目录的临时文件，校验通过后再原子重命名，中断的下载不会留下残缺文件。
+// 镜像可以来自输入目录中的配置文件，因此下载的内容必须与固定的 SHA-256 一致，未固定时不下载
+func Fetch(a Artifact, destPath string, opts Options) error {
+	if a.SHA256 == "" {
+		return errNoDigest
+	}
+	urls, err := candidateURLs(a, opts.Mirrors)
+	if err != nil {
+		return err
+	}
+
+	client := opts.Client
+	if client == nil {
+		// 默认 Transport 已支持 HTTP(S)_PROXY，并带有连接、TLS 握手超时和 HTTP/2
+		client = &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+			Timeout:   5 * time.Minute,
+		}
+	}
+
+	var errs []string
+	for _, url := range urls {
+		backoff := opts.Backoff
+		for attempt := 0; attempt <= opts.Retries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+
+			err := fetchOnce(client, url, destPath, a.SHA256, opts.Progress)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+
+			// 文件不存在或内容校验失败时重试无意义，直接换下一个地址
+			if _, ok := err.(permanentError); ok {
+				break
+			}
+		}
+	}
+	return fmt.Errorf(i18n.T("所有下载地址均失败:\n  %s"), strings.Join(errs, "\n  "))
+}
+
+// permanentError 重试无法解决的错误
+type permanentError struct{ error }
+
+// fetchOnce 从一个地址下载一次
+func fetchOnce(client *http.Client, url, destPath, sha256Hex string, progress io.Writer) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf(i18n.T("状态码: %d"), resp.StatusCode)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return permanentError{err}
+		}
+		return err
+	}
+
+	var body io.Reader = resp.Body
+	if progress != nil {
+		pr := &progressReader{r: resp.Body, total: resp.ContentLength, out: progress}
+		defer pr.finish()
+		body = pr
+	}
+
+	if err := writeVerified(body, destPath, sha256Hex); err != nil {
+		if _, ok := err.(verifyError); ok {
+			return permanentError{err}
+		}
+		return err
+	}
+	return nil
+}
+
+// candidateURLs 按优先级生成下载地址列表
+func candidateURLs(a Artifact, mirrors []string) ([]string, error) {
+	fileName := path.Base(a.URL)
+	if a.Maven != "" {
+		mavenPath, err := MavenPath(a.Maven)
+		if err != nil {
+			return nil, err
+		}
+		fileName = path.Base(mavenPath)
+	}
+
+	var urls []string
+	for _, mirror := range mirrors {
+		switch {
+		case strings.Contains(mirror, "{file}"):
+			urls = append(urls, strings.ReplaceAll(mirror, "{file}", fileName))
+		case a.Maven != "":
+			url, _ := MavenURL(mirror, a.Maven)
+			urls = append(urls, url)
+		}
+	}
+	if a.URL != "" {
+		urls = append(urls, a.URL)
+	}
+	if a.Maven != "" {
+		url, _ := MavenURL(MavenCentral, a.Maven)
+		urls = append(urls, url)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf(i18n.T("没有可用的下载地址"))
+	}
+	return urls, nil
+}
+
+// MavenPath 将 Maven 坐标转换为仓库内的相对路径
+// 例如 org.benf:cfr:0.152 -> org/benf/cfr/0.152/cfr-0.152.jar
+func MavenPath(coords string) (string, error) {
+	parts := strings.Split(coords, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return "", fmt.Errorf(i18n.T("无效的 Maven 坐标: %s"), coords)
+	}
+	group, artifact, version := parts[0], parts[1], parts[2]
+	if group == "" || artifact == "" || version == "" {
+		return "", fmt.Errorf(i18n.T("无效的 Maven 坐标: %s"), coords)
+	}
+
+	fileName := artifact + "-" + version
+	if len(parts) == 4 && parts[3] != "" {
+		fileName += "-" + parts[3]
+	}
+	return path.Join(strings.ReplaceAll(group, ".", "/"), artifact, version, fileName+".jar"), nil
+}
+
+// MavenURL 拼接 Maven 仓库中 artifact 的下载地址
+func MavenURL(repo, coords string) (string, error) {
+	p, err := MavenPath(coords)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(repo, "/") + "/" + p, nil
+}
+
+// progressReader 在读取时输出下载进度
+type progressReader struct {
+	r       io.Reader
+	out     io.Writer
+	total   int64
+	read    int64
+	printed time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if time.Since(p.printed) >= 200*time.Millisecond {
+		p.print()
+	}
+	return n, err
+}
+
+func (p *progressReader) print() {
+	p.printed = time.Now()
+	const mb = 1024 * 1024
+	if p.total > 0 {
+		percent := float64(p.read) / float64(p.total) * 100
+		width := 30
+		filled := int(float64(width) * float64(p.read) / float64(p.total))
+		if filled > width {
+			filled = width
+		}
+		fmt.Fprintf(p.out, i18n.T("\r下载进度: [%s%s] %.1f%% (%.1f/%.1f MB)"),
+			strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+			percent, float64(p.read)/mb, float64(p.total)/mb)
+	} else {
+		fmt.Fprintf(p.out, i18n.T("\r下载进度: %.1f MB"), float64(p.read)/mb)
+	}
+}
+
+// finish 输出最终进度并换行
+func (p *progressReader) finish() {
+	p.print()
+	fmt.Fprintln(p.out)
+}
+
+// Install 从本地文件安装到 destPath，用于无法联网的环境，文件必须与 sha256Hex 一致
+func Install(srcPath, destPath, sha256Hex string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return writeVerified(src, destPath, sha256Hex)
+}
+
+// Verify 校验已有文件的 SHA-256 和 JAR 结构，sha256Hex 为空时校验失败
+func Verify(path, sha256Hex string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if err := checkDigest(h.Sum(nil), sha256Hex); err != nil {
+		return err
+	}
+	return CheckJar(path)
+}
+
+// writeVerified 写入临时文件、校验、再重命名到目标路径
+func writeVerified(r io.Reader, destPath, sha256Hex string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf(i18n.T("创建目录失败: %v"), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), filepath.Base(destPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf(i18n.T("创建临时文件失败: %v"), err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后为空操作
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("保存文件失败: %v"), err)
+	}
+
+	if err := checkDigest(h.Sum(nil), sha256Hex); err != nil {
+		return verifyError{err}
+	}
+	if err := CheckJar(tmpPath); err != nil {
+		return verifyError{err}
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf(i18n.T("保存文件失败: %v"), err)
+	}
+	return nil
+}
+
+// verifyError 下载内容校验失败
+type verifyError struct{ error }
+
+// errNoDigest 没有固定 SHA-256，无法确认文件未被篡改
+var errNoDigest = verifyError{i18n.Error("未固定 SHA-256，拒绝使用未经校验的 JAR")}
+
+// checkDigest 比较摘要，期望值为空时返回 errNoDigest
+func checkDigest(sum []byte, sha256Hex string) error {
+	if sha256Hex == "" {
+		return errNoDigest
+	}
+	if actual := hex.EncodeToString(sum); !strings.EqualFold(actual, sha256Hex) {
+		return fmt.Errorf(i18n.T("SHA-256 校验失败: 期望 %s, 实际 %s"), sha256Hex, actual)
+	}
+	return nil
+}
+
+// CheckJar 确认文件是完整的 ZIP/JAR，可识别被截断的文件
+// 只用于用户显式指定的 JAR，下载和缓存的 JAR 使用 Verify
+func CheckJar(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("不是有效的 JAR 文件: %v"), err)
+	}
+	return r.Close()
+}
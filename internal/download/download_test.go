@@ -0,0 +1,189 @@
+package download
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testJar 生成一个最小的 JAR 文件内容
+func testJar(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("META-INF/MANIFEST.MF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("Manifest-Version: 1.0\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	jar := testJar(t)
+	src := filepath.Join(dir, "src.jar")
+	if err := os.WriteFile(src, jar, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		sha256  string
+		wantErr bool
+	}{
+		{"摘要匹配", jar, digest(jar), false},
+		{"未固定摘要", jar, "", true},
+		{"摘要不匹配", jar, digest([]byte("other")), true},
+		{"文件被截断", jar[:len(jar)/2], digest(jar), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(src, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(t.TempDir(), "engine", "cfr.jar")
+
+			err := Install(src, dest, tt.sha256)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Install() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			_, statErr := os.Stat(dest)
+			if tt.wantErr && statErr == nil {
+				t.Error("校验失败时不应留下目标文件")
+			}
+			if !tt.wantErr {
+				if statErr != nil {
+					t.Errorf("目标文件不存在: %v", statErr)
+				}
+				if err := Verify(dest, tt.sha256); err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			}
+
+			leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(dest), "*.tmp"))
+			if len(leftovers) > 0 {
+				t.Errorf("残留临时文件: %v", leftovers)
+			}
+		})
+	}
+}
+
+func TestMavenURL(t *testing.T) {
+	tests := []struct {
+		repo     string
+		coords   string
+		expected string
+		wantErr  bool
+	}{
+		{"https://nexus.example.com/repository/maven-public/", "org.benf:cfr:0.152",
+			"https://nexus.example.com/repository/maven-public/org/benf/cfr/0.152/cfr-0.152.jar", false},
+		{MavenCentral, "org.vineflower:vineflower:1.10.1:slim",
+			"https://repo1.maven.org/maven2/org/vineflower/vineflower/1.10.1/vineflower-1.10.1-slim.jar", false},
+		{MavenCentral, "org.benf:cfr", "", true},
+		{MavenCentral, "org.benf::0.152", "", true},
+	}
+
+	for _, tt := range tests {
+		result, err := MavenURL(tt.repo, tt.coords)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MavenURL(%q) error = %v, wantErr %v", tt.coords, err, tt.wantErr)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("MavenURL(%q) = %q, want %q", tt.coords, result, tt.expected)
+		}
+	}
+}
+
+func TestFetchMirrors(t *testing.T) {
+	jar := testJar(t)
+	var hits []string
+	failures := 1
+
+	mux := http.NewServeMux()
+	// 返回错误内容的镜像：校验失败后不重试，直接换下一个地址
+	mux.HandleFunc("/bad/", func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		w.Write([]byte("not a jar"))
+	})
+	// 缺失文件的镜像：404 不重试
+	mux.HandleFunc("/empty/", func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		http.NotFound(w, r)
+	})
+	// 首次返回 503，重试后成功
+	mux.HandleFunc("/nexus/", func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jar)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	artifact := Artifact{
+		URL:    server.URL + "/official/cfr-0.152.jar",
+		Maven:  "org.benf:cfr:0.152",
+		SHA256: digest(jar),
+	}
+	opts := Options{
+		Mirrors: []string{server.URL + "/bad/{file}", server.URL + "/empty", server.URL + "/nexus/"},
+		Retries: 2,
+		Backoff: time.Millisecond,
+	}
+
+	dest := filepath.Join(t.TempDir(), "cfr.jar")
+	if err := Fetch(artifact, dest, opts); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	expected := []string{
+		"/bad/cfr-0.152.jar",
+		"/empty/org/benf/cfr/0.152/cfr-0.152.jar",
+		"/nexus/org/benf/cfr/0.152/cfr-0.152.jar",
+		"/nexus/org/benf/cfr/0.152/cfr-0.152.jar",
+	}
+	if !reflect.DeepEqual(hits, expected) {
+		t.Errorf("请求顺序 = %v, want %v", hits, expected)
+	}
+	if err := Verify(dest, digest(jar)); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestFetchAllFail(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "cfr.jar")
+	err := Fetch(Artifact{URL: server.URL + "/cfr.jar", SHA256: digest([]byte("cfr"))}, dest, Options{Retries: 3, Backoff: time.Millisecond})
+	if err == nil {
+		t.Fatal("Fetch() 应返回错误")
+	}
+	if _, statErr := os.Stat(dest); statErr == nil {
+		t.Error("下载失败时不应留下目标文件")
+	}
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/cfr"
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// DefaultEngine 默认使用的反编译引擎
+const DefaultEngine = "cfr"
+
+// Decompiler 反编译引擎接口
+type Decompiler interface {
+	// Name 返回引擎名称
+	Name() string
+	// Decompile 反编译单个 class 文件到输出目录（按包结构生成 .java）
+	Decompile(ctx context.Context, inputPath string, outputDir string) error
+	// GetVersion 返回引擎版本信息
+	GetVersion() (string, error)
+}
+
+// BatchItem 批量反编译中的单个 class 文件
+type BatchItem = cfr.BatchItem
+
+// BatchDecompiler 可在单个进程中反编译多个 class 的引擎
+type BatchDecompiler interface {
+	DecompileBatch(ctx context.Context, items []BatchItem, outputDir string) []error
+}
+
+// DaemonDecompiler 支持常驻进程的引擎
+type DaemonDecompiler interface {
+	StartDaemon(size int) error
+	Close() error
+}
+
+// Options 创建引擎时的可选配置
+type Options struct {
+	CFRJar     string            // 指定 CFR JAR 路径，为空时使用环境变量 EMORAD_CFR_JAR 或自动下载
+	CFROptions map[string]string // 传给 CFR 的选项，其他引擎忽略
+	Download   download.Options  // 下载引擎 JAR 时使用的镜像、代理和重试配置
+	Console    *console.Console  // 控制台输出，nil 时写入 color.Output
+}
+
+// factories 已注册的引擎构造函数
+var factories = map[string]func(opts Options) (Decompiler, error){
+	"cfr": func(opts Options) (Decompiler, error) {
+		m, err := cfr.NewManager(cfr.Options{JarPath: opts.CFRJar, Download: opts.Download, Options: opts.CFROptions, Console: opts.Console})
+		if err != nil {
+			return nil, err
+		}
+		return &cfrEngine{m}, nil
+	},
+	"procyon":    newProcyon,
+	"vineflower": newVineflower,
+}
+
+// jarSpecs 以 JAR 分发的引擎（CFR 由 cfr 包单独管理）
+var jarSpecs = map[string]jarSpec{
+	"procyon":    procyonSpec,
+	"vineflower": vineflowerSpec,
+}
+
+// aliases 引擎别名
+var aliases = map[string]string{
+	"fernflower": "vineflower",
+}
+
+// New 按名称创建反编译引擎
+func New(name string, opts Options) (Decompiler, error) {
+	name, err := resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return factories[name](opts)
+}
+
+// Install 从本地文件安装引擎 JAR，返回安装后的路径
+func Install(name, srcPath string) (string, error) {
+	name, err := resolve(name)
+	if err != nil {
+		return "", err
+	}
+	if name == "cfr" {
+		return cfr.Install(srcPath)
+	}
+
+	spec := jarSpecs[name]
+	path, err := jarPath(spec)
+	if err != nil {
+		return "", err
+	}
+	if err := download.Install(srcPath, path, spec.sha256); err != nil {
+		return "", fmt.Errorf(i18n.T("安装%s失败: %v"), name, err)
+	}
+	return path, nil
+}
+
+// resolve 规范化引擎名称并处理别名
+func resolve(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultEngine
+	}
+	if target, ok := aliases[name]; ok {
+		name = target
+	}
+	if _, ok := factories[name]; !ok {
+		return "", fmt.Errorf(i18n.T("不支持的反编译引擎: %s (可选: %s)"), name, strings.Join(Names(), ", "))
+	}
+	return name, nil
+}
+
+// Names 返回所有可用的引擎名称
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Identity 返回引擎的名称、版本和 JAR（或命令）的 SHA-256，用于区分不同构建的同名引擎
+// 带备用引擎时同时包含备用引擎的信息
+func Identity(d Decompiler) string {
+	if f, ok := d.(*FallbackDecompiler); ok {
+		return Identity(f.Primary) + " fallback=" + Identity(f.Fallback)
+	}
+
+	version, _ := d.GetVersion()
+	identity := fmt.Sprintf("%s version=%q", d.Name(), version)
+	if p, ok := d.(interface{ Path() string }); ok {
+		if f, err := os.Open(p.Path()); err == nil {
+			h := sha256.New()
+			_, err := io.Copy(h, f)
+			f.Close()
+			if err == nil {
+				identity += " sha256=" + hex.EncodeToString(h.Sum(nil))
+			}
+		}
+	}
+	return identity
+}
+
+// DecompileBatch 批量反编译，引擎不支持批量模式时逐个处理
+func DecompileBatch(ctx context.Context, d Decompiler, items []BatchItem, outputDir string) []error {
+	if bd, ok := d.(BatchDecompiler); ok {
+		return bd.DecompileBatch(ctx, items, outputDir)
+	}
+
+	errs := make([]error, len(items))
+	for i, item := range items {
+		errs[i] = d.Decompile(ctx, item.InputPath, outputDir)
+	}
+	return errs
+}
+
+// cfrEngine 将 cfr.Manager 适配为 Decompiler
+type cfrEngine struct {
+	*cfr.Manager
+}
+
+func (e *cfrEngine) Name() string {
+	return "cfr"
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	expected := []string{"cfr", "procyon", "vineflower"}
+	if names := Names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Names() = %v, want %v", names, expected)
+	}
+}
+
+func TestNewUnknownEngine(t *testing.T) {
+	if _, err := New("jad", Options{}); err == nil {
+		t.Error("New(\"jad\") 应返回错误")
+	}
+}
+
+func TestInstallUnknownEngine(t *testing.T) {
+	if _, err := Install("jad", "jad.jar"); err == nil {
+		t.Error("Install(\"jad\") 应返回错误")
+	}
+}
+
+func TestInstallPinnedDigest(t *testing.T) {
+	var official, tampered bytes.Buffer
+	for buf, content := range map[*bytes.Buffer]string{&official: "Manifest-Version: 1.0\n", &tampered: "Manifest-Version: 1.0\nX-Tampered: true\n"} {
+		w := zip.NewWriter(buf)
+		f, _ := w.Create("META-INF/MANIFEST.MF")
+		f.Write([]byte(content))
+		w.Close()
+	}
+	sum := sha256.Sum256(official.Bytes())
+
+	spec := jarSpecs["procyon"]
+	defer func() { jarSpecs["procyon"] = spec }()
+	pinned := spec
+	pinned.sha256 = hex.EncodeToString(sum[:])
+	jarSpecs["procyon"] = pinned
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{"与固定摘要一致", official.Bytes(), false},
+		{"结构完整但被篡改", tampered.Bytes(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join(home, "procyon.jar")
+			os.WriteFile(src, tt.content, 0644)
+			if _, err := Install("procyon", src); (err != nil) != tt.wantErr {
+				t.Errorf("Install() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// pathDecompiler 带 JAR 路径的测试引擎
+type pathDecompiler struct {
+	fakeDecompiler
+	path string
+}
+
+func (d *pathDecompiler) Path() string { return d.path }
+
+func TestIdentity(t *testing.T) {
+	dir := t.TempDir()
+	official, custom := filepath.Join(dir, "cfr.jar"), filepath.Join(dir, "cfr-patched.jar")
+	os.WriteFile(official, []byte("official"), 0644)
+	os.WriteFile(custom, []byte("patched"), 0644)
+
+	a := Identity(&pathDecompiler{fakeDecompiler{name: "cfr"}, official})
+	b := Identity(&pathDecompiler{fakeDecompiler{name: "cfr"}, custom})
+	if a == b {
+		t.Errorf("same identity for different JARs: %s", a)
+	}
+	fallback := Identity(NewFallback(&pathDecompiler{fakeDecompiler{name: "cfr"}, official}, &fakeDecompiler{name: "vineflower"}))
+	if !strings.HasPrefix(fallback, a) || !strings.Contains(fallback, "fallback=vineflower") {
+		t.Errorf("Identity(fallback) = %s", fallback)
+	}
+}
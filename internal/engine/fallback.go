@@ -0,0 +1,234 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// failureMarkers 各引擎在方法无法反编译时写入源码的标记
+var failureMarkers = [][]byte{
+	[]byte("This method has failed to decompile"), // CFR
+	[]byte("This method could not be decompiled"), // Procyon
+	[]byte("$FF: Couldn't be decompiled"),         // Vineflower/Fernflower
+}
+
+// EngineReporter 能够报告实际生成源码的引擎的反编译器
+type EngineReporter interface {
+	DecompileWithEngine(ctx context.Context, inputPath string, outputDir string) (string, error)
+	DecompileBatchWithEngine(ctx context.Context, items []BatchItem, outputDir string) ([]string, []error)
+}
+
+// DecompileWithEngine 反编译单个 class 并返回实际使用的引擎名称
+func DecompileWithEngine(ctx context.Context, d Decompiler, inputPath, outputDir string) (string, error) {
+	if r, ok := d.(EngineReporter); ok {
+		return r.DecompileWithEngine(ctx, inputPath, outputDir)
+	}
+	return d.Name(), d.Decompile(ctx, inputPath, outputDir)
+}
+
+// DecompileBatchWithEngine 批量反编译并返回每个 class 实际使用的引擎名称
+func DecompileBatchWithEngine(ctx context.Context, d Decompiler, items []BatchItem, outputDir string) ([]string, []error) {
+	if r, ok := d.(EngineReporter); ok {
+		return r.DecompileBatchWithEngine(ctx, items, outputDir)
+	}
+
+	names := make([]string, len(items))
+	for i := range names {
+		names[i] = d.Name()
+	}
+	return names, DecompileBatch(ctx, d, items, outputDir)
+}
+
+// FallbackDecompiler 主引擎失败或输出包含失败标记时，用备用引擎重试并保留较好的结果
+type FallbackDecompiler struct {
+	Primary  Decompiler
+	Fallback Decompiler
+}
+
+// NewFallback 创建带备用引擎的反编译器
+func NewFallback(primary, fallback Decompiler) *FallbackDecompiler {
+	return &FallbackDecompiler{Primary: primary, Fallback: fallback}
+}
+
+func (f *FallbackDecompiler) Name() string {
+	return f.Primary.Name()
+}
+
+func (f *FallbackDecompiler) GetVersion() (string, error) {
+	return f.Primary.GetVersion()
+}
+
+func (f *FallbackDecompiler) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	_, err := f.DecompileWithEngine(ctx, inputPath, outputDir)
+	return err
+}
+
+// DecompileWithEngine 先将两个引擎的结果写入临时目录，比较后再合并到输出目录
+func (f *FallbackDecompiler) DecompileWithEngine(ctx context.Context, inputPath string, outputDir string) (string, error) {
+	tempDir, err := os.MkdirTemp("", "emorad-fallback-")
+	if err != nil {
+		return f.Primary.Name(), fmt.Errorf(i18n.T("创建临时目录失败: %v"), err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	primaryDir := filepath.Join(tempDir, "primary")
+	primaryErr := decompileInto(ctx, f.Primary, inputPath, primaryDir)
+	primary := scoreDir(primaryDir, primaryErr)
+	if primary.ok && primary.markers == 0 {
+		return f.Primary.Name(), copyTree(primaryDir, outputDir)
+	}
+	if ctx.Err() != nil {
+		return f.Primary.Name(), ctx.Err()
+	}
+
+	fallbackDir := filepath.Join(tempDir, "fallback")
+	fallback := scoreDir(fallbackDir, decompileInto(ctx, f.Fallback, inputPath, fallbackDir))
+	if fallback.betterThan(primary) {
+		return f.Fallback.Name(), copyTree(fallbackDir, outputDir)
+	}
+
+	if primaryErr != nil {
+		return f.Primary.Name(), primaryErr
+	}
+	return f.Primary.Name(), copyTree(primaryDir, outputDir)
+}
+
+func (f *FallbackDecompiler) DecompileBatch(ctx context.Context, items []BatchItem, outputDir string) []error {
+	_, errs := f.DecompileBatchWithEngine(ctx, items, outputDir)
+	return errs
+}
+
+// DecompileBatchWithEngine 主引擎批量处理后，仅对失败或含失败标记的 class 调用备用引擎
+func (f *FallbackDecompiler) DecompileBatchWithEngine(ctx context.Context, items []BatchItem, outputDir string) ([]string, []error) {
+	names, errs := DecompileBatchWithEngine(ctx, f.Primary, items, outputDir)
+
+	for i, item := range items {
+		primary := scoreFile(filepath.Join(outputDir, item.SourcePath), errs[i])
+		if primary.ok && primary.markers == 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		tempDir, err := os.MkdirTemp("", "emorad-fallback-")
+		if err != nil {
+			continue
+		}
+		fallback := scoreDir(tempDir, decompileInto(ctx, f.Fallback, item.InputPath, tempDir))
+		if fallback.betterThan(primary) {
+			if err := copyTree(tempDir, outputDir); err == nil {
+				names[i] = f.Fallback.Name()
+				errs[i] = nil
+			}
+		}
+		os.RemoveAll(tempDir)
+	}
+	return names, errs
+}
+
+// decompileInto 在新建的目录中反编译
+func decompileInto(ctx context.Context, d Decompiler, inputPath, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return d.Decompile(ctx, inputPath, dir)
+}
+
+// sourceScore 反编译结果的质量评估
+type sourceScore struct {
+	ok      bool // 是否成功生成了源码
+	markers int  // 失败标记数量
+}
+
+// betterThan 成功优于失败，失败标记少的优于多的；相同时不替换
+func (s sourceScore) betterThan(other sourceScore) bool {
+	if !s.ok {
+		return false
+	}
+	return !other.ok || s.markers < other.markers
+}
+
+// scoreDir 统计目录中所有 .java 文件的失败标记
+func scoreDir(dir string, err error) sourceScore {
+	if err != nil {
+		return sourceScore{}
+	}
+
+	var score sourceScore
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".java") {
+			return nil
+		}
+		if s := scoreFile(path, nil); s.ok {
+			score.ok = true
+			score.markers += s.markers
+		}
+		return nil
+	})
+	return score
+}
+
+// scoreFile 统计单个 .java 文件的失败标记，文件不存在时视为失败
+func scoreFile(path string, err error) sourceScore {
+	if err != nil {
+		return sourceScore{}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return sourceScore{}
+	}
+	return sourceScore{ok: true, markers: CountFailureMarkers(content)}
+}
+
+// CountFailureMarkers 统计源码中的失败标记数量
+func CountFailureMarkers(content []byte) int {
+	count := 0
+	for _, marker := range failureMarkers {
+		count += bytes.Count(content, marker)
+	}
+	return count
+}
+
+// copyTree 将 src 目录下的文件复制到 dst，已存在的文件会被覆盖
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(destPath, 0755)
+		}
+		return copyFile(path, destPath)
+	})
+}
+
+// copyFile 复制单个文件，已存在的文件会被覆盖
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, srcFile)
+	return err
+}
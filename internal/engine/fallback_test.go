@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeDecompiler 将固定内容写入 Foo.java 的测试引擎
+type fakeDecompiler struct {
+	name    string
+	content string
+	err     error
+}
+
+func (f *fakeDecompiler) Name() string                { return f.name }
+func (f *fakeDecompiler) GetVersion() (string, error) { return f.name, nil }
+
+func (f *fakeDecompiler) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	if f.err != nil {
+		return f.err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "Foo.java"), []byte(f.content), 0644)
+}
+
+func TestFallbackDecompiler(t *testing.T) {
+	const stub = "// This method has failed to decompile.\n"
+
+	tests := []struct {
+		name       string
+		primary    *fakeDecompiler
+		fallback   *fakeDecompiler
+		wantEngine string
+		wantSource string
+		wantErr    bool
+	}{
+		{
+			name:       "主引擎成功时不重试",
+			primary:    &fakeDecompiler{name: "cfr", content: "class Foo {}"},
+			fallback:   &fakeDecompiler{name: "vineflower", err: errors.New("不应调用")},
+			wantEngine: "cfr",
+			wantSource: "class Foo {}",
+		},
+		{
+			name:       "主引擎失败时使用备用引擎",
+			primary:    &fakeDecompiler{name: "cfr", err: errors.New("失败")},
+			fallback:   &fakeDecompiler{name: "vineflower", content: "class Foo { }"},
+			wantEngine: "vineflower",
+			wantSource: "class Foo { }",
+		},
+		{
+			name:       "备用引擎失败标记更少时替换",
+			primary:    &fakeDecompiler{name: "cfr", content: stub + stub},
+			fallback:   &fakeDecompiler{name: "vineflower", content: stub},
+			wantEngine: "vineflower",
+			wantSource: stub,
+		},
+		{
+			name:       "备用引擎没有更好时保留主引擎结果",
+			primary:    &fakeDecompiler{name: "cfr", content: stub},
+			fallback:   &fakeDecompiler{name: "vineflower", err: errors.New("失败")},
+			wantEngine: "cfr",
+			wantSource: stub,
+		},
+		{
+			name:       "两个引擎都失败",
+			primary:    &fakeDecompiler{name: "cfr", err: errors.New("失败")},
+			fallback:   &fakeDecompiler{name: "vineflower", err: errors.New("失败")},
+			wantEngine: "cfr",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			d := NewFallback(tt.primary, tt.fallback)
+
+			engineName, err := d.DecompileWithEngine(context.Background(), "Foo.class", outputDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecompileWithEngine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if engineName != tt.wantEngine {
+				t.Errorf("engine = %q, want %q", engineName, tt.wantEngine)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "Foo.java"))
+			if err != nil {
+				t.Fatalf("读取输出失败: %v", err)
+			}
+			if string(content) != tt.wantSource {
+				t.Errorf("source = %q, want %q", content, tt.wantSource)
+			}
+		})
+	}
+}
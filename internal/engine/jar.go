@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+
+	"github.com/jiaozhu/emorad/internal/classfile"
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// jarSpec 以 JAR 形式分发的反编译引擎
+type jarSpec struct {
+	name        string // 引擎名称，同时作为 ~/.emorad 下的缓存目录名
+	version     string
+	fileName    string
+	downloadURL string
+	maven       string // Maven 坐标，为空时不从 Maven 仓库下载
+	sha256      string // 发布包的 SHA-256，为空时不使用下载或缓存的 JAR
+	// args 生成传给 JAR 的命令行参数
+	args func(inputPath, outputDir string) []string
+	// flat 为 true 时单个 class 的源码直接写在输出目录下，不含包目录
+	flat bool
+}
+
+// jarEngine 通过 java -jar 运行的反编译引擎
+type jarEngine struct {
+	spec     jarSpec
+	javaPath string
+	jarPath  string
+}
+
+// newJarEngine 检查 Java 环境并准备引擎 JAR
+func newJarEngine(spec jarSpec, opts Options) (*jarEngine, error) {
+	javaPath, err := exec.LookPath("java")
+	if err != nil {
+		return nil, fmt.Errorf(opts.Console.T("未找到Java环境,请安装Java: %v"), err)
+	}
+
+	jarPath, err := ensureJar(spec, opts.Download, opts.Console)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Console.Green(opts.Console.T("✓ 使用%s JAR: %s"), spec.name, jarPath)
+	return &jarEngine{spec: spec, javaPath: javaPath, jarPath: jarPath}, nil
+}
+
+func (e *jarEngine) Name() string {
+	return e.spec.name
+}
+
+// Decompile 反编译class文件
+// 不按包结构输出的引擎先写入临时目录，再按 class 文件中的类名移动到包目录
+func (e *jarEngine) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	if !e.spec.flat {
+		return e.run(ctx, inputPath, outputDir)
+	}
+
+	tempDir, err := os.MkdirTemp("", "emorad-"+e.spec.name+"-")
+	if err != nil {
+		return fmt.Errorf(i18n.T("创建临时目录失败: %v"), err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := e.run(ctx, inputPath, tempDir); err != nil {
+		return err
+	}
+
+	cf, err := classfile.ParseFile(inputPath)
+	if err != nil {
+		return copyTree(tempDir, outputDir)
+	}
+	packageDir := filepath.Join(outputDir, filepath.FromSlash(path.Dir(cf.ThisClass)))
+	return filepath.Walk(tempDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if err := os.MkdirAll(packageDir, 0755); err != nil {
+			return err
+		}
+		return copyFile(file, filepath.Join(packageDir, info.Name()))
+	})
+}
+
+// run 运行引擎 JAR
+func (e *jarEngine) run(ctx context.Context, inputPath string, outputDir string) error {
+	args := append([]string{"-Dfile.encoding=UTF-8", "-jar", e.jarPath}, e.spec.args(inputPath, outputDir)...)
+	cmd := exec.CommandContext(ctx, e.javaPath, args...)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, string(output))
+	}
+	return nil
+}
+
+// GetVersion 返回引擎版本
+func (e *jarEngine) GetVersion() (string, error) {
+	return e.spec.name + " " + e.spec.version, nil
+}
+
+// Path 返回引擎 JAR 的路径
+func (e *jarEngine) Path() string {
+	return e.jarPath
+}
+
+// jarPath 返回引擎 JAR 的缓存路径 (~/.emorad/<engine>/<file>)
+func jarPath(spec jarSpec) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("无法获取用户主目录: %v"), err)
+	}
+	return filepath.Join(homeDir, ".emorad", spec.name, spec.fileName), nil
+}
+
+// ensureJar 确保引擎 JAR 存在于 ~/.emorad/<engine>/ 下且与固定的 SHA-256 一致,否则重新下载
+// 未固定 SHA-256 时不使用缓存，也不下载
+func ensureJar(spec jarSpec, opts download.Options, out *console.Console) (string, error) {
+	if spec.sha256 == "" {
+		return "", fmt.Errorf(out.T("%s v%s 未固定 SHA-256，不能使用下载或缓存的 JAR"), spec.name, spec.version)
+	}
+	path, err := jarPath(spec)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		err := download.Verify(path, spec.sha256)
+		if err == nil {
+			return path, nil
+		}
+		out.Warn(out.T("[WARN] 缓存的%s JAR校验失败,重新下载: %v"), spec.name, err)
+	}
+
+	out.Cyan(out.T("正在下载%s反编译器 v%s..."), spec.name, spec.version)
+	artifact := download.Artifact{URL: spec.downloadURL, Maven: spec.maven, SHA256: spec.sha256}
+	if err := download.Fetch(artifact, path, opts); err != nil {
+		return "", fmt.Errorf(out.T("下载%s失败: %v"), spec.name, err)
+	}
+
+	out.Green(out.T("✓ %s下载完成"), spec.name)
+	return path, nil
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/download"
+)
+
+// packagedClass 生成只包含类名和父类的 class 文件，name 如 com/acme/Foo
+func packagedClass(name string) []byte {
+	var b bytes.Buffer
+	w := func(v any) { binary.Write(&b, binary.BigEndian, v) }
+	utf8 := func(s string) {
+		w(uint8(1))
+		w(uint16(len(s)))
+		b.WriteString(s)
+	}
+
+	w(uint32(0xCAFEBABE))
+	w(uint16(0))
+	w(uint16(52))
+	w(uint16(5))
+	utf8(name)
+	w(uint8(7))
+	w(uint16(1))
+	utf8("java/lang/Object")
+	w(uint8(7))
+	w(uint16(3))
+	for _, v := range []uint16{0x21, 2, 4, 0, 0, 0, 0} {
+		w(v)
+	}
+	return b.Bytes()
+}
+
+// writePackagedClasses 在 dir 下写入两个同名但不同包的 class，返回它们的路径
+func writePackagedClasses(t *testing.T, dir string) []string {
+	var paths []string
+	for _, name := range []string{"com/acme/Foo", "com/other/Foo"} {
+		path := filepath.Join(dir, "classes", filepath.FromSlash(name)+".class")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, packagedClass(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestJarEngineFlatOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	dir := t.TempDir()
+	// 模拟 Vineflower：把 Foo.java 直接写在输出目录下，内容为输入路径
+	java := filepath.Join(dir, "java")
+	script := "#!/bin/sh\nfor last; do :; done\nfor arg; do [ \"$arg\" != \"$last\" ] && input=\"$arg\"; done\necho \"$input\" > \"$last/Foo.java\"\n"
+	if err := os.WriteFile(java, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &jarEngine{spec: vineflowerSpec, javaPath: java, jarPath: "vineflower.jar"}
+	output := filepath.Join(dir, "out")
+	inputs := writePackagedClasses(t, dir)
+	for _, input := range inputs {
+		if err := e.Decompile(context.Background(), input, output); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, source := range []string{"com/acme/Foo.java", "com/other/Foo.java"} {
+		data, err := os.ReadFile(filepath.Join(output, filepath.FromSlash(source)))
+		if err != nil {
+			t.Fatalf("%s not written: %v", source, err)
+		}
+		if strings.TrimSpace(string(data)) != inputs[i] {
+			t.Errorf("%s = %q, want output of %s", source, data, inputs[i])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(output, "Foo.java")); err == nil {
+		t.Error("Foo.java left in output root")
+	}
+}
+
+// TestVineflowerPackagedClass 使用真实的 Vineflower 反编译带包名的 class
+// 需要 Java 和 ~/.emorad/vineflower 下的 JAR（emorad engine install vineflower），否则跳过
+func TestVineflowerPackagedClass(t *testing.T) {
+	javaPath, err := exec.LookPath("java")
+	if err != nil {
+		t.Skip("未找到 Java")
+	}
+	jar, err := jarPath(vineflowerSpec)
+	if err != nil {
+		t.Skip(err)
+	}
+	if _, err := os.Stat(jar); err != nil {
+		t.Skip("未安装 Vineflower JAR")
+	}
+
+	e := &jarEngine{spec: vineflowerSpec, javaPath: javaPath, jarPath: jar}
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out")
+	for _, input := range writePackagedClasses(t, dir) {
+		if err := e.Decompile(context.Background(), input, output); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pkg := range []string{"com.acme", "com.other"} {
+		source := filepath.Join(output, strings.ReplaceAll(pkg, ".", string(filepath.Separator)), "Foo.java")
+		data, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatalf("%s not written: %v", source, err)
+		}
+		if !strings.Contains(string(data), "package "+pkg+";") {
+			t.Errorf("%s:\n%s", source, data)
+		}
+	}
+}
+
+// roundTripFunc 不访问网络，所有请求都由函数应答
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// TestEnsureJarProductionDigest 使用代码中固定的 SHA-256，下载和缓存的 JAR 与之不一致时拒绝使用
+func TestEnsureJarProductionDigest(t *testing.T) {
+	var unofficial bytes.Buffer
+	w := zip.NewWriter(&unofficial)
+	f, _ := w.Create("META-INF/MANIFEST.MF")
+	f.Write([]byte("Manifest-Version: 1.0\nCreated-By: mirror\n"))
+	w.Close()
+
+	for _, spec := range []jarSpec{procyonSpec, vineflowerSpec} {
+		t.Run(spec.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			path, err := jarPath(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, unofficial.Bytes(), 0644)
+
+			client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(unofficial.Bytes())), Request: r}, nil
+			})}
+			opts := download.Options{Client: client, Mirrors: []string{"https://mirror.example.com/{file}"}}
+			if _, err := ensureJar(spec, opts, console.New(io.Discard, false, "")); err == nil {
+				t.Errorf("ensureJar(%s) used an unverified JAR", spec.name)
+			}
+		})
+	}
+}
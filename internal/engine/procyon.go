@@ -0,0 +1,16 @@
+package engine
+
+// Procyon 反编译器，对 Java 8 lambda 和泛型的还原效果较好
+var procyonSpec = jarSpec{
+	name:        "procyon",
+	version:     "0.6.0",
+	fileName:    "procyon-decompiler-0.6.0.jar",
+	downloadURL: "https://github.com/mstrobel/procyon/releases/download/v0.6.0/procyon-decompiler-0.6.0.jar",
+	args: func(inputPath, outputDir string) []string {
+		return []string{"-o", outputDir, inputPath}
+	},
+}
+
+func newProcyon(opts Options) (Decompiler, error) {
+	return newJarEngine(procyonSpec, opts)
+}
@@ -0,0 +1,18 @@
+package engine
+
+// Vineflower 反编译器（Fernflower 的社区维护分支），对 Kotlin 和新版本 Java 支持较好
+var vineflowerSpec = jarSpec{
+	name:        "vineflower",
+	version:     "1.10.1",
+	fileName:    "vineflower-1.10.1.jar",
+	downloadURL: "https://github.com/Vineflower/vineflower/releases/download/1.10.1/vineflower-1.10.1.jar",
+	maven:       "org.vineflower:vineflower:1.10.1",
+	args: func(inputPath, outputDir string) []string {
+		return []string{inputPath, outputDir}
+	},
+	flat: true, // 单个 class 输出为 <outputDir>/Foo.java
+}
+
+func newVineflower(opts Options) (Decompiler, error) {
+	return newJarEngine(vineflowerSpec, opts)
+}
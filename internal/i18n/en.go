@@ -0,0 +1,275 @@
+package i18n
+
+// en 英文消息目录，键为代码中的中文原文
+// 新增面向用户的消息时需要同时在此添加译文，格式化动词的顺序须与原文一致
+var en = map[string]string{
+	// i18n
+	"不支持的语言 %q，可选 zh、en": "unsupported language %q, choose zh or en",
+
+	// cmd/emorad
+	"无效的大小: %q":                        "invalid size: %q",
+	"无效的百分比: %q":                       "invalid percentage: %q",
+	"配置项 %s 无效: %v":                    "invalid config value %s: %v",
+	"Error: 无效的日志格式 %q，可选 text、ndjson": "Error: invalid log format %q, choose text or ndjson",
+	"[CONFIG] 使用配置文件: %s":              "[CONFIG] Using config file: %s",
+	"[CONFIG] 使用配置方案: %s":              "[CONFIG] Using profile: %s",
+	"Error: 未找到配置文件 %s，无法使用方案 %q":      "Error: no %s found, cannot use profile %q",
+	"✓ %s 已安装: %s":                     "✓ %s installed: %s",
+	"解析 %s 失败: %v":                     "failed to parse %s: %v",
+	"读取压缩包失败: %v":                      "failed to read archive: %v",
+	"类名:     %s":                       "Class:       %s",
+	"类型:     %s\n":                     "Kind:        %s\n",
+	"访问标志: 0x%04X\n":                   "Access:      0x%04X\n",
+	"字节码:   %d.%d (Java %s)\n":         "Bytecode:    %d.%d (Java %s)\n",
+	"父类:     %s\n":                     "Superclass:  %s\n",
+	"接口:     %s\n":                     "Interface:   %s\n",
+	"注解:     @%s\n":                    "Annotation:  @%s\n",
+	"源文件:   %s\n":                      "Source file: %s\n",
+	"输入路径: %s":                         "Input: %s",
+	"class 数量: %d\n":                   "Classes:   %d\n",
+	"包数量:     %d\n":                    "Packages:  %d\n",
+	"依赖 JAR:   %d\n":                   "Lib JARs:  %d\n",
+	"字节码版本:":                           "Bytecode versions:",
+	"类列表:":                             "Classes:",
+	"[WARN] %d 个 class 无法解析:":          "[WARN] %d classes could not be parsed:",
+	"未知":                               "unknown",
+
+	// cache
+	"无法获取用户主目录: %v": "cannot determine home directory: %v",
+	"创建缓存目录失败: %v":  "failed to create cache directory: %v",
+
+	// cfr
+	"常驻进程不可用":                              "daemon unavailable",
+	"系统 CFR 命令不支持常驻进程模式":                   "the system CFR command does not support daemon mode",
+	"[WARN] 常驻进程启动失败，将在使用时重试: %v":          "[WARN] Daemon failed to start, will retry on demand: %v",
+	"✓ 已启动 %d 个常驻 CFR 进程":                  "✓ Started %d CFR daemons",
+	"创建常驻进程目录失败: %v":                       "failed to create daemon directory: %v",
+	"写入常驻进程源码失败: %v":                       "failed to write daemon source: %v",
+	"启动常驻进程失败: %v":                         "failed to start daemon: %v",
+	"意外的握手响应: %s":                          "unexpected handshake response: %s",
+	"等待就绪超时":                               "timed out waiting for daemon",
+	"常驻进程未就绪: %v %s":                       "daemon not ready: %v %s",
+	"[WARN] 常驻进程异常，正在重启: %v":               "[WARN] Daemon failed, restarting: %v",
+	"响应编号不匹配: %d != %d":                    "response id mismatch: %d != %d",
+	"无法解析响应: %v":                           "cannot parse response: %v",
+	"未找到Java环境,请安装Java: %v":                "Java not found, please install Java: %v",
+	"指定的CFR JAR不可用 %s: %v":                 "configured CFR JAR is not usable %s: %v",
+	"✓ 使用指定的CFR JAR: %s":                   "✓ Using configured CFR JAR: %s",
+	"✓ 找到系统CFR命令: %s":                      "✓ Found system CFR command: %s",
+	"系统未安装CFR命令,尝试使用CFR JAR文件...":          "CFR command not installed, trying the CFR JAR...",
+	"✓ 使用CFR JAR: %s":                      "✓ Using CFR JAR: %s",
+	"安装CFR失败: %v":                          "failed to install CFR: %v",
+	"[WARN] 缓存的CFR JAR校验失败,重新下载: %v":       "[WARN] Cached CFR JAR failed verification, downloading again: %v",
+	"正在下载CFR反编译器 v%s...":                   "Downloading CFR decompiler v%s...",
+	"✓ CFR下载完成":                            "✓ CFR downloaded",
+	"下载CFR失败: %v":                          "failed to download CFR: %v",
+	"无效的CFR选项: %q":                         "invalid CFR option: %q",
+	"CFR选项 outputdir 由 --output 控制，不能单独指定": "CFR option outputdir is controlled by --output and cannot be set",
+	"未生成源文件: %s":                           "no source file generated: %s",
+	"Java未安装或不在PATH中\n请安装Java: https://www.java.com/\n错误: %v": "Java is not installed or not on PATH\nInstall Java: https://www.java.com/\nError: %v",
+	"✓ Java环境检测成功": "✓ Java found",
+
+	// classfile
+	"不是有效的 class 文件":      "not a valid class file",
+	"class 文件不完整":         "truncated class file",
+	"无效的 this_class":      "invalid this_class",
+	"未知的常量池标签 %d (位置 %d)": "unknown constant pool tag %d (index %d)",
+	"未知的注解元素类型 %q":        "unknown annotation element tag %q",
+	"属性长度 %d 超出范围":        "attribute length %d out of range",
+
+	// config
+	"读取配置文件失败: %v":              "failed to read config file: %v",
+	"解析配置文件失败 %s: %v":           "failed to parse config file %s: %v",
+	"配置文件 %s 中没有方案 %q，可用方案: %s": "config file %s has no profile %q, available profiles: %s",
+
+	// decompile
+	"[JDK] 项目 Java 版本: %d（应用 class 文件最多的编译目标）":                          "[JDK] Project Java version: %d (most common application class target)",
+	"[JDK] 项目 Java 版本: %d（MANIFEST.MF 的 %s）":                            "[JDK] Project Java version: %d (from MANIFEST.MF %s)",
+	"[JDK] 无法确定 Java 版本，使用 %d":                                          "[JDK] Could not detect the Java version, using %d",
+	"[WARN] Gradle 项目使用 maven 目录结构，忽略 --layout flat":                    "[WARN] Gradle projects use the maven layout, ignoring --layout flat",
+	"[CONFIG] 目录结构: src/main/java、src/main/resources、src/main/webapp":   "[CONFIG] Layout: src/main/java, src/main/resources, src/main/webapp",
+	"无效的目录结构 %q，可选 flat、maven":                                          "invalid layout %q, expected flat or maven",
+	"无效的 Gradle DSL %q，可选 groovy、kotlin":                                "invalid Gradle DSL %q, expected groovy or kotlin",
+	"[CONFIG] 生成 Gradle 项目: %s DSL":                                     "[CONFIG] Generate Gradle project: %s DSL",
+	"\n[PROCESS] 生成 Gradle 构建文件...":                                     "\n[PROCESS] Generating Gradle build files...",
+	"[WARN] 生成 Gradle 构建文件失败: %v":                                       "[WARN] Failed to generate Gradle build files: %v",
+	"[CONFIG] 生成 Eclipse 项目: 已启用":                                       "[CONFIG] Generate Eclipse project: enabled",
+	"[CONFIG] 生成 VS Code 项目: 已启用":                                       "[CONFIG] Generate VS Code project: enabled",
+	"\n[PROCESS] 生成 Eclipse 项目配置...":                                    "\n[PROCESS] Generating Eclipse project files...",
+	"[WARN] 生成 Eclipse 项目配置失败: %v":                                      "[WARN] Failed to generate Eclipse project files: %v",
+	"[OK] Eclipse 项目配置已生成，可通过 File > Import > Existing Projects 导入: %s": "[OK] Eclipse project generated, import it via File > Import > Existing Projects: %s",
+	"\n[PROCESS] 生成 VS Code 项目配置...":                                    "\n[PROCESS] Generating VS Code project files...",
+	"[WARN] 生成 VS Code 项目配置失败: %v":                                      "[WARN] Failed to generate VS Code project files: %v",
+	"[OK] VS Code 项目配置已生成: %s":                                          "[OK] VS Code settings generated: %s",
+	"[OK] Gradle 构建文件已生成: %d 个依赖，其中 %d 个使用 Maven 坐标":                    "[OK] Gradle build files generated: %d dependencies, %d with Maven coordinates",
+	"[CONFIG] 生成 Maven 项目: 已启用":                                         "[CONFIG] Generate Maven project: enabled",
+	"\n[PROCESS] 生成 Maven pom.xml...":                                   "\n[PROCESS] Generating Maven pom.xml...",
+	"[WARN] 生成 Maven pom.xml 失败: %v":                                    "[WARN] Failed to generate Maven pom.xml: %v",
+	"[OK] pom.xml 已生成: %d 个依赖，其中 %d 个使用 Maven 坐标":                       "[OK] pom.xml generated: %d dependencies, %d with Maven coordinates",
+	"[WARN] 以下依赖未找到 Maven 坐标，以本地文件引用 libs 目录: %s":                       "[WARN] No Maven coordinates found for these dependencies, referenced as local files in libs: %s",
+	"\n[START] 开始反编译...":                                                "\n[START] Decompiling...",
+	"[ERROR] 无法访问输入路径: %v":                                              "[ERROR] Cannot access input path: %v",
+	"[ERROR] 不支持的文件类型: %s":                                              "[ERROR] Unsupported file type: %s",
+	"不支持的文件类型: %s":                                                      "unsupported file type: %s",
+	"[FILTER] 包含过滤: %v":                                                 "[FILTER] Include: %v",
+	"[FILTER] 排除过滤: %d 条规则":                                             "[FILTER] Exclude: %d rules",
+	"[CONFIG] 跳过依赖库: 已启用":                                               "[CONFIG] Skip libraries: enabled",
+	"[FILTER] JAR 名称过滤: %v":                                             "[FILTER] JAR name filter: %v",
+	"[CONFIG] 复制配置文件: 已启用":                                              "[CONFIG] Copy resources: enabled",
+	"[CONFIG] 复制依赖 JAR: 已启用":                                            "[CONFIG] Copy lib JARs: enabled",
+	"[CONFIG] 生成 IDEA 项目: 已启用":                                          "[CONFIG] Generate IDEA project: enabled",
+	"[CONFIG] 反编译引擎: %s":                                                "[CONFIG] Decompiler engine: %s",
+	"[CONFIG] 备用反编译引擎: %s":                                              "[CONFIG] Fallback engine: %s",
+	"[CONFIG] 单个 class 超时: %s":                                          "[CONFIG] Per-class timeout: %s",
+	"[CONFIG] 批量反编译: 每个进程 %d 个 class":                                   "[CONFIG] Batch mode: %d classes per process",
+	"[CONFIG] 常驻反编译进程: 已启用":                                             "[CONFIG] Daemon mode: enabled",
+	"[CONFIG] CFR 选项: %v":                                               "[CONFIG] CFR options: %v",
+	"[INIT] 初始化反编译器...":                                                 "[INIT] Initializing decompiler...",
+	"[ERROR] 初始化反编译引擎失败: %v":                                            "[ERROR] Failed to initialize decompiler engine: %v",
+	"\n[TIP] 提示:":                                          "\n[TIP] Hints:",
+	"   1. 请确保已安装Java环境":                                   "   1. Make sure Java is installed",
+	"   2. 工具会自动下载反编译器 JAR":                                "   2. The decompiler JAR is downloaded automatically",
+	"   3. 离线环境可使用 emorad engine install --from <file> 安装": "   3. Offline, install it with emorad engine install --from <file>",
+	"   4. 或手动安装: brew install cfr-decompiler":             "   4. Or install manually: brew install cfr-decompiler",
+	"[WARN] 常驻进程模式不可用，使用独立进程: %v":                          "[WARN] Daemon mode unavailable, using one process per class: %v",
+	"[WARN] %s 引擎不支持常驻进程模式，使用独立进程":                         "[WARN] Engine %s does not support daemon mode, using one process per class",
+	"[WARN] 初始化备用引擎失败，不启用重试: %v":                           "[WARN] Failed to initialize fallback engine, retries disabled: %v",
+	"[WARN] 无法使用缓存: %v":                                    "[WARN] Cache unavailable: %v",
+	"[CONFIG] 反编译缓存: %s":                                   "[CONFIG] Decompile cache: %s",
+	"[ERROR] 创建输出目录失败: %v":                                 "[ERROR] Failed to create output directory: %v",
+	"[DETECT] 检测到目录,使用目录处理器":                               "[DETECT] Directory detected, using directory processor",
+	"[DETECT] 检测到JAR文件,使用JAR处理器":                           "[DETECT] JAR file detected, using JAR processor",
+	"[DETECT] 检测到WAR文件,使用WAR处理器":                           "[DETECT] WAR file detected, using WAR processor",
+	"[DETECT] 检测到CLASS文件,使用CLASS处理器":                       "[DETECT] CLASS file detected, using CLASS processor",
+	"[RESUME] 已合并上次运行的 %d 个结果":                             "[RESUME] Merged %d results from the previous run",
+	"[RESUME] 未找到可恢复的运行记录，从头开始":                            "[RESUME] Nothing to resume, starting from scratch",
+	"[WARN] 单个 class 文件无需恢复，忽略 --resume":                   "[WARN] Nothing to resume for a single class file, ignoring --resume",
+	"\n[WARN] 反编译已中断: %v，生成部分报告":                           "\n[WARN] Decompilation interrupted: %v, writing partial report",
+	"\n[ERROR] 处理失败: %v":                                   "\n[ERROR] Processing failed: %v",
+	"\n[PROCESS] 处理 Unicode 转义序列...":                       "\n[PROCESS] Decoding Unicode escapes...",
+	"[WARN] Unicode 后处理警告: %v":                             "[WARN] Unicode post-processing: %v",
+	"[OK] Unicode 后处理完成: 处理 %d 文件, 修复 %d 文件":               "[OK] Unicode post-processing done: %d files checked, %d fixed",
+	"\n[PROCESS] 生成 IDEA 项目配置...":                          "\n[PROCESS] Generating IDEA project...",
+	"[WARN] 生成 IDEA 项目配置失败: %v":                            "[WARN] Failed to generate IDEA project: %v",
+	"[OK] IDEA 项目配置已生成，可直接用 IDEA 打开: %s":                   "[OK] IDEA project generated, open it in IDEA: %s",
+	"全部 %d 个源文件反编译失败":                                      "all %d source files failed to decompile",
+	"失败率 %.2f%% (%d/%d) 超过阈值 %.2f%%":                       "failure rate %.2f%% (%d/%d) exceeds threshold %.2f%%",
+
+	// download
+	"所有下载地址均失败:\n  %s":                     "all download URLs failed:\n  %s",
+	"状态码: %d":                              "status code: %d",
+	"没有可用的下载地址":                            "no download URL available",
+	"无效的 Maven 坐标: %s":                     "invalid Maven coordinates: %s",
+	"\r下载进度: [%s%s] %.1f%% (%.1f/%.1f MB)": "\rDownloading: [%s%s] %.1f%% (%.1f/%.1f MB)",
+	"\r下载进度: %.1f MB":                      "\rDownloading: %.1f MB",
+	"创建目录失败: %v":                           "failed to create directory: %v",
+	"创建临时文件失败: %v":                         "failed to create temporary file: %v",
+	"保存文件失败: %v":                           "failed to save file: %v",
+	"未固定 SHA-256，拒绝使用未经校验的 JAR":            "No pinned SHA-256, refusing to use an unverified JAR",
+	"CFR v%s 未固定 SHA-256，不能使用下载或缓存的 JAR，请通过 --cfr-jar 或 EMORAD_CFR_JAR 指定": "CFR v%s has no pinned SHA-256, so downloaded or cached JARs cannot be used; specify one with --cfr-jar or EMORAD_CFR_JAR",
+	"%s v%s 未固定 SHA-256，不能使用下载或缓存的 JAR":                                    "%s v%s has no pinned SHA-256, so downloaded or cached JARs cannot be used",
+	"SHA-256 校验失败: 期望 %s, 实际 %s":                                           "SHA-256 mismatch: expected %s, got %s",
+	"不是有效的 JAR 文件: %v":                                                     "not a valid JAR file: %v",
+
+	// engine
+	"安装%s失败: %v":                    "failed to install %s: %v",
+	"不支持的反编译引擎: %s (可选: %s)":        "unsupported decompiler engine: %s (available: %s)",
+	"创建临时目录失败: %v":                  "failed to create temporary directory: %v",
+	"✓ 使用%s JAR: %s":                "✓ Using %s JAR: %s",
+	"[WARN] 缓存的%s JAR校验失败,重新下载: %v": "[WARN] Cached %s JAR failed verification, downloading again: %v",
+	"正在下载%s反编译器 v%s...":             "Downloading %s decompiler v%s...",
+	"下载%s失败: %v":                    "failed to download %s: %v",
+	"✓ %s下载完成":                      "✓ %s downloaded",
+
+	// processor
+	"复制 web 内容失败: %s - %v":                    "Failed to copy web content: %s - %v",
+	"[OK] 复制了 %d 个 web 内容文件到 webapp 目录":       "[OK] Copied %d web content files to the webapp directory",
+	"不支持的 Gradle DSL %q，可选 groovy、kotlin":     "unsupported Gradle DSL %q, expected groovy or kotlin",
+	"读取依赖 JAR 失败: %v":                         "failed to read dependency JARs: %v",
+	"非法文件路径: %s":                              "illegal file path: %s",
+	"✓ %s (缓存)":                               "✓ %s (cached)",
+	"反编译超时: 批次处理超时":                           "decompile timeout: batch timed out",
+	"✗ %s (超时)":                               "✗ %s (timeout)",
+	"反编译失败: ":                                 "decompile failed: ",
+	"无效的正则过滤规则 %q: %v":                        "invalid regex filter %q: %v",
+	"过滤规则不能为空":                                "filter rule must not be empty",
+	"创建 .idea 目录失败: %v":                       "failed to create .idea directory: %v",
+	"创建 .settings 目录失败: %v":                   "failed to create .settings directory: %v",
+	"创建 .vscode 目录失败: %v":                     "failed to create .vscode directory: %v",
+	"创建 libs 目录失败: %v":                        "failed to create libs directory: %v",
+	"条目数 %d 超过限制 %d":                          "%d entries exceed the limit of %d",
+	"嵌套层级 %d 超过限制 %d":                         "nesting depth %d exceeds the limit of %d",
+	"压缩比 %.0f 超过限制 %.0f":                      "compression ratio %.0f exceeds the limit of %.0f",
+	"解压总大小已超过限制":                              "total unpacked size limit already reached",
+	"解压总大小超过限制 %d 字节":                         "total unpacked size exceeds the limit of %d bytes",
+	"反编译超时: 超过 %s":                            "decompile timeout: exceeded %s",
+	"反编译失败: %v":                               "decompile failed: %v",
+	"打开JAR文件失败: %v":                           "failed to open JAR file: %v",
+	"正在处理JAR文件: %s":                           "Processing JAR file: %s",
+	"读取JAR文件失败: %v":                           "failed to read JAR file: %v",
+	"复制配置文件失败: %s - %v":                       "Failed to copy resource: %s - %v",
+	"[OK] 复制了 %d 个配置文件":                       "[OK] Copied %d resource files",
+	"解压JAR文件失败: %v":                           "failed to extract JAR file: %v",
+	"[FILTER] 过滤后: %d/%d 个 class 文件需要处理":      "[FILTER] After filtering: %d/%d class files to process",
+	"[RESUME] 跳过上次已完成的 %d 个源文件":               "[RESUME] Skipping %d source files completed in the previous run",
+	"[WARN] 复制依赖 JAR 失败: %v":                  "[WARN] Failed to copy lib JARs: %v",
+	"[OK] 复制了 %d 个依赖 JAR 到 libs 目录":           "[OK] Copied %d lib JARs to libs",
+	"处理嵌套JAR: %s":                             "Processing nested JAR: %s",
+	"处理嵌套JAR失败: %v":                           "Failed to process nested JAR: %v",
+	"正在处理目录: %s":                              "Processing directory: %s",
+	"扫描目录失败: %v":                              "failed to scan directory: %v",
+	"[SCAN] 扫描结果: %d个JAR, %d个WAR, %d个CLASS文件": "[SCAN] Found %d JAR, %d WAR and %d CLASS files",
+	"[WARN] 未找到任何需要反编译的文件":                    "[WARN] Nothing to decompile",
+	"[RESUME] 跳过上次已完成的 %s":                    "[RESUME] Skipping %s, completed in the previous run",
+	"处理WAR文件: %s":                             "Processing WAR file: %s",
+	"处理JAR文件: %s":                             "Processing JAR file: %s",
+	"处理压缩包失败: %v":                             "Failed to process archive: %v",
+	"读取文件失败: %w":                              "failed to read file: %w",
+	"写入文件失败: %w":                              "failed to write file: %w",
+
+	// report
+	"[JDK] class 版本分布: %s": "[JDK] Class versions: %s",
+	"Java 版本分布":            "Java Versions",
+	"Java 版本":              "Java Version",
+	"源文件数":                 "Source Files",
+	"读取运行日志失败: %v":         "failed to read run journal: %v",
+	"运行日志属于其他输入 %s，请去掉 --resume 或更换输出目录": "run journal belongs to another input %s, drop --resume or use another output directory",
+	"创建运行日志失败: %v":            "failed to create run journal: %v",
+	"%s 不是有效的运行日志":            "%s is not a valid run journal",
+	"\r反编译进度: %.1f%% (%d/%d)": "\rProgress: %.1f%% (%d/%d)",
+	"\n[LIMIT] 已跳过 %s: %s":    "\n[LIMIT] Skipped %s: %s",
+	"\n[OK] 反编译完成！":           "\n[OK] Decompilation finished!",
+	"\n============================================\n              反编译报告摘要\n============================================\n\n输入路径: %s\n输出路径: %s\n总耗时:   %.2f 秒\n\n文件统计:\n   - 总文件数: %d\n   - 成功数量: %d\n   - 失败数量: %d\n   - 成功率: %.2f%%\n\n============================================\n": "\n============================================\n              Decompile Summary\n============================================\n\nInput:    %s\nOutput:   %s\nDuration: %.2f s\n\nFiles:\n   - Total:        %d\n   - Succeeded:    %d\n   - Failed:       %d\n   - Success rate: %.2f%%\n\n============================================\n",
+	"[CACHE] 其中 %d 个来自缓存，%d 个重新反编译": "[CACHE] %d from cache, %d decompiled",
+	"[RESUME] 其中 %d 个结果来自上次中断的运行":   "[RESUME] %d results carried over from the interrupted run",
+	"[LIMIT] %d 项内容因资源限制被跳过，详见报告":   "[LIMIT] %d items skipped by resource limits, see the report",
+	"[WARN] 保存详细报告失败: %v":           "[WARN] Failed to save detailed reports: %v",
+	"[INFO] 详细报告已保存到: %s/reports/":  "[INFO] Detailed reports saved to: %s/reports/",
+	"反编译报告":                     "Decompile Report",
+	"生成时间":                      "Generated",
+	"总文件数":                      "Total files",
+	"成功":                        "Succeeded",
+	"失败":                        "Failed",
+	"成功率":                       "Success rate",
+	"耗时":                        "Duration",
+	"处理详情":                      "Details",
+	"文件名":                       "File",
+	"包名":                        "Package",
+	"状态":                        "Status",
+	"引擎":                        "Engine",
+	"耗时(秒)":                     "Time (s)",
+	"错误信息":                      "Error",
+	" <small>(+%d 内部类)</small>": " <small>(+%d inner classes)</small>",
+	"缓存":                        "Cached",
+	"资源限制":                      "Resource Limits",
+	"压缩包":                       "Archive",
+	"条目":                        "Entry",
+	"类型":                        "Kind",
+	"说明":                        "Message",
+	"输入":                        "Input",
+	"输出":                        "Output",
+
+	// pkg/emorad
+	"从 fs.FS 读取输入时不支持恢复运行": "resuming is not supported for fs.FS inputs",
+	"无效的 fs.FS 路径: %s":     "invalid fs.FS path: %s",
+}
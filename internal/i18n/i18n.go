@@ -0,0 +1,106 @@
+// Package i18n 提供面向用户的消息的中英文翻译
+//
+// 消息以中文原文作为键，英文译文保存在 en.go 的消息目录中；
+// 格式化参数由调用方传入，如 color.Red(i18n.T("处理失败: %v"), err)
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// current 当前语言，默认中文
+var current atomic.Value
+
+func init() {
+	current.Store(Chinese)
+}
+
+// Lang 返回当前语言
+func Lang() string {
+	return current.Load().(string)
+}
+
+// SetLang 设置当前语言，lang 为空时根据环境变量检测
+func SetLang(lang string) error {
+	if lang == "" {
+		lang = Detect()
+	}
+	switch lang {
+	case Chinese, English:
+		current.Store(lang)
+		return nil
+	default:
+		return fmt.Errorf(T("不支持的语言 %q，可选 zh、en"), lang)
+	}
+}
+
+// Detect 按 LC_ALL、LC_MESSAGES、LANG 的顺序检测语言
+// zh_CN.UTF-8 等中文区域为中文，其他明确设置的区域为英文；未设置或为 C、POSIX 时使用中文
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		return detectLocale(value)
+	}
+	return Chinese
+}
+
+// detectLocale 将区域名称转换为语言
+func detectLocale(locale string) string {
+	locale = strings.ToLower(locale)
+	switch {
+	case strings.HasPrefix(locale, "zh"):
+		return Chinese
+	case locale == "c" || locale == "posix" || strings.HasPrefix(locale, "c."):
+		return Chinese
+	default:
+		return English
+	}
+}
+
+// T 返回消息在当前语言下的文本，没有译文时返回原文
+func T(msg string) string {
+	return In(Lang(), msg)
+}
+
+// In 返回消息在 lang 下的文本，没有译文时返回原文
+func In(lang, msg string) string {
+	if lang == English {
+		if translated, ok := en[msg]; ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// HTMLLang 返回当前语言对应的 HTML lang 属性值
+func HTMLLang() string {
+	return HTMLLangOf(Lang())
+}
+
+// HTMLLangOf 返回 lang 对应的 HTML lang 属性值
+func HTMLLangOf(lang string) string {
+	if lang == English {
+		return "en"
+	}
+	return "zh-CN"
+}
+
+// Error 可翻译的错误，用于包级别的哨兵错误：
+// 变量在设置语言之前初始化，因此在调用 Error 时才翻译
+type Error string
+
+func (e Error) Error() string {
+	return T(string(e))
+}
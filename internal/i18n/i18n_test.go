@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		want   string
+	}{
+		{"简体中文", "zh_CN.UTF-8", Chinese},
+		{"繁体中文", "zh_TW", Chinese},
+		{"英文", "en_US.UTF-8", English},
+		{"其他语言", "de_DE.UTF-8", English},
+		{"C 区域", "C.UTF-8", Chinese},
+		{"POSIX", "POSIX", Chinese},
+	}
+
+	for _, tt := range tests {
+		if got := detectLocale(tt.locale); got != tt.want {
+			t.Errorf("%s: detectLocale(%q) = %s, want %s", tt.name, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLang(Chinese)
+
+	if err := SetLang(English); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("成功"); got != "Succeeded" {
+		t.Errorf("T(成功) = %q", got)
+	}
+	if got := T("没有译文的消息"); got != "没有译文的消息" {
+		t.Errorf("缺少译文时应返回原文, got %q", got)
+	}
+	if got := Error("不是有效的 class 文件").Error(); got != "not a valid class file" {
+		t.Errorf("Error = %q", got)
+	}
+	if err := SetLang("fr"); err == nil {
+		t.Error("不支持的语言应返回错误")
+	}
+}
+
+// formatVerbs 匹配格式化动词，%% 不计入
+var formatVerbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func verbs(s string) []string {
+	var result []string
+	for _, verb := range formatVerbs.FindAllString(s, -1) {
+		if verb != "%%" {
+			result = append(result, verb)
+		}
+	}
+	return result
+}
+
+// TestCatalogue 检查代码中的每条中文消息都有英文译文，且格式化动词一致
+func TestCatalogue(t *testing.T) {
+	messages := map[string]string{}
+	for _, root := range []string{"../../internal", "../../cmd", "../../pkg"} {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") ||
+				strings.HasSuffix(path, "_test.go") || filepath.Base(path) == "en.go" {
+				return nil
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				if _, ok := n.(*ast.Field); ok {
+					return false // 结构体标签
+				}
+				lit, ok := n.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				if s, err := strconv.Unquote(lit.Value); err == nil && hasHan(s) {
+					messages[s] = path
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for msg, path := range messages {
+		translated, ok := en[msg]
+		if !ok {
+			t.Errorf("%s: 缺少译文 %q", path, msg)
+			continue
+		}
+		if !reflect.DeepEqual(verbs(msg), verbs(translated)) {
+			t.Errorf("%s: 格式化动词不一致 %q -> %q", path, msg, translated)
+		}
+	}
+}
+
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/classfile"
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// resourceExts 需要复制到 resources 目录的配置文件类型
+var resourceExts = map[string]bool{
+	".properties": true,
+	".yml":        true,
+	".yaml":       true,
+	".xml":        true,
+	".json":       true,
+	".conf":       true,
+	".config":     true,
+	".txt":        true,
+	".sql":        true,
+	".sh":         true,
+}
+
+// archiveEntries 按类型分组的压缩包条目
+type archiveEntries struct {
+	classes    []*zip.File // .class 文件
+	nestedJars []*zip.File // BOOT-INF/lib 或 WEB-INF/lib 下的依赖 JAR
+	resources  []*zip.File // BOOT-INF/classes 或 WEB-INF/classes 下的配置文件
+	webapp     []*zip.File // WEB-INF/classes、WEB-INF/lib 以外的非 class 文件，WAR 中的 web 内容
+}
+
+// scanArchive 只读取压缩包目录，不解压任何内容
+func scanArchive(r *zip.Reader) archiveEntries {
+	var entries archiveEntries
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(f.Name))
+		switch {
+		case ext == ".class":
+			entries.classes = append(entries.classes, f)
+		case ext == ".jar":
+			if isLibEntry(f.Name) {
+				entries.nestedJars = append(entries.nestedJars, f)
+			}
+		case resourceExts[ext]:
+			if strings.Contains(f.Name, "BOOT-INF/classes/") || strings.Contains(f.Name, "WEB-INF/classes/") {
+				entries.resources = append(entries.resources, f)
+			}
+		}
+		if ext != ".class" && isWebappEntry(f.Name) {
+			entries.webapp = append(entries.webapp, f)
+		}
+	}
+	return entries
+}
+
+// isWebappEntry 判断 WAR 条目是否属于 web 内容，即 src/main/webapp 中的文件
+// 编译输出、依赖 JAR 和打包时生成的 META-INF 文件不属于 web 内容
+func isWebappEntry(name string) bool {
+	for _, prefix := range []string{"WEB-INF/classes/", "WEB-INF/lib/", "META-INF/maven/"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return name != "META-INF/MANIFEST.MF"
+}
+
+// isLibEntry 判断条目是否位于依赖库目录
+func isLibEntry(name string) bool {
+	return strings.Contains(name, "BOOT-INF/lib") || strings.Contains(name, "WEB-INF/lib")
+}
+
+// entryPath 计算条目解压到 dest 下的路径，拒绝跳出 dest 的条目
+func entryPath(dest, name string) (string, error) {
+	fpath := filepath.Join(dest, filepath.FromSlash(name))
+	if !strings.HasPrefix(fpath, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf(i18n.T("非法文件路径: %s"), name)
+	}
+	return fpath, nil
+}
+
+// extractEntry 将单个条目写入 destPath
+func extractEntry(f *zip.File, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	outFile, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	_, err = io.Copy(outFile, rc)
+	return err
+}
+
+// archiveClassPath 返回条目中 class 按类名推算的路径（如 com/acme/Foo.class），不依赖条目所在的目录
+// 压缩比超出限制或无法解析时返回空
+func archiveClassPath(f *zip.File, limits ArchiveLimits) string {
+	if limits.checkRatio(f) != nil {
+		return ""
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	cf, err := classfile.Parse(rc)
+	if err != nil {
+		return ""
+	}
+	return cf.ThisClass + ".class"
+}
+
+// copyResourceEntry 将配置文件条目直接写入 resourcesDir，
+// 去掉 BOOT-INF/classes 或 WEB-INF/classes 前缀
+func copyResourceEntry(f *zip.File, resourcesDir string) error {
+	relPath := f.Name
+	if idx := strings.Index(relPath, "BOOT-INF/classes/"); idx != -1 {
+		relPath = relPath[idx+len("BOOT-INF/classes/"):]
+	} else if idx := strings.Index(relPath, "WEB-INF/classes/"); idx != -1 {
+		relPath = relPath[idx+len("WEB-INF/classes/"):]
+	}
+
+	destPath, err := entryPath(resourcesDir, relPath)
+	if err != nil {
+		return err
+	}
+	return extractEntry(f, destPath)
+}
+
+// openNestedJar 以 io.ReaderAt 打开嵌套 JAR，不写入临时文件
+// 未压缩存储的条目（Spring Boot 的默认方式）直接引用外层文件的对应区间，
+// 压缩存储的条目读入内存
+func openNestedJar(outer io.ReaderAt, f *zip.File) (io.ReaderAt, int64, error) {
+	if f.Method == zip.Store {
+		offset, err := f.DataOffset()
+		if err != nil {
+			return nil, 0, err
+		}
+		size := int64(f.UncompressedSize64)
+		return io.NewSectionReader(outer, offset, size), size, nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
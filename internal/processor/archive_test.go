@@ -0,0 +1,293 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// recordingDecompiler 记录收到的 class 路径的测试引擎
+type recordingDecompiler struct {
+	mu      sync.Mutex
+	classes []string
+}
+
+func (d *recordingDecompiler) Name() string                { return "recording" }
+func (d *recordingDecompiler) GetVersion() (string, error) { return "", nil }
+
+func (d *recordingDecompiler) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	if _, err := os.Stat(inputPath); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.classes = append(d.classes, filepath.Base(inputPath))
+	return nil
+}
+
+// zipEntry 测试压缩包中的条目
+type zipEntry struct {
+	name   string
+	data   []byte
+	method uint16
+}
+
+func buildZip(t *testing.T, entries []zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: e.name, Method: e.method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJarProcessorStreamsEntries(t *testing.T) {
+	stored := buildZip(t, []zipEntry{{name: "com/acme/lib/Stored.class", data: []byte("class")}})
+	deflated := buildZip(t, []zipEntry{{name: "com/acme/lib/Deflated.class", data: []byte("class"), method: zip.Deflate}})
+	skipped := buildZip(t, []zipEntry{{name: "com/other/Skipped.class", data: []byte("class")}})
+
+	fatJar := buildZip(t, []zipEntry{
+		{name: "BOOT-INF/classes/com/acme/App$1.class", data: []byte("class")},
+		{name: "BOOT-INF/classes/com/acme/App.class", data: []byte("class"), method: zip.Deflate},
+		{name: "BOOT-INF/classes/org/springframework/Config.class", data: []byte("class")},
+		{name: "BOOT-INF/classes/application.yml", data: []byte("server:\n  port: 8080\n")},
+		{name: "BOOT-INF/lib/acme-stored.jar", data: stored},
+		{name: "BOOT-INF/lib/acme-deflated.jar", data: deflated, method: zip.Deflate},
+		{name: "BOOT-INF/lib/other.jar", data: skipped},
+	})
+	jarPath := filepath.Join(t.TempDir(), "app.jar")
+	if err := os.WriteFile(jarPath, fatJar, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filterConfig := NewDefaultFilterConfig()
+	filterConfig.JarIncludes = []string{"acme"}
+
+	decompiler := &recordingDecompiler{}
+	outputDir := t.TempDir()
+	run := RunContext{ResourcesDir: filepath.Join(outputDir, "resources"), LibsDir: filepath.Join(outputDir, "libs")}
+	rpt := report.New(jarPath, outputDir)
+	if err := NewJarProcessor(decompiler, 2, filterConfig, run).Process(context.Background(), jarPath, outputDir, rpt); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	sort.Strings(decompiler.classes)
+	if got := strings.Join(decompiler.classes, ","); got != "App.class,Deflated.class,Stored.class" {
+		t.Errorf("反编译的 class = %s", got)
+	}
+	if len(rpt.Results) != 3 {
+		t.Errorf("len(Results) = %d, want 3", len(rpt.Results))
+	}
+	for _, result := range rpt.Results {
+		if result.ClassName == "App.class" && result.InnerClasses != 1 {
+			t.Errorf("App.class InnerClasses = %d, want 1", result.InnerClasses)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "resources", "application.yml")); err != nil {
+		t.Errorf("配置文件未复制: %v", err)
+	}
+	for _, name := range []string{"acme-stored.jar", "acme-deflated.jar", "other.jar"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "libs", name)); err != nil {
+			t.Errorf("依赖 JAR 未复制: %v", err)
+		}
+	}
+}
+
+func TestJarProcessorClassNameFilter(t *testing.T) {
+	// 混淆或重新打包后条目路径与类名不一致，过滤规则按 class 文件中的类名匹配
+	jar := buildZip(t, []zipEntry{
+		{name: "obf/a.class", data: minimalClass("com/acme/Foo")},
+		{name: "com/acme/b.class", data: minimalClass("org/other/Bar"), method: zip.Deflate},
+	})
+	jarPath := filepath.Join(t.TempDir(), "app.jar")
+	if err := os.WriteFile(jarPath, jar, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filterConfig := NewDefaultFilterConfig()
+	filterConfig.Includes = []string{"com/acme/"}
+	decompiler := &recordingDecompiler{}
+	outputDir := t.TempDir()
+	rpt := report.New(jarPath, outputDir)
+	if err := NewJarProcessor(decompiler, 1, filterConfig, RunContext{}).Process(context.Background(), jarPath, outputDir, rpt); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if got := strings.Join(decompiler.classes, ","); got != "a.class" {
+		t.Errorf("反编译的 class = %s, want a.class", got)
+	}
+	if len(rpt.Results) != 1 || rpt.Results[0].PackageName != "com/acme" {
+		t.Errorf("Results = %+v", rpt.Results)
+	}
+}
+
+func TestJarProcessorLimits(t *testing.T) {
+	inner := buildZip(t, []zipEntry{{name: "com/acme/Inner.class", data: []byte("class")}})
+	middle := buildZip(t, []zipEntry{{name: "BOOT-INF/lib/inner.jar", data: inner}})
+	bomb := bytes.Repeat([]byte{0}, 2<<20)
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		limits  ArchiveLimits
+		kind    string
+		classes int
+	}{
+		{"条目数超限", []zipEntry{
+			{name: "com/acme/A.class", data: []byte("a")},
+			{name: "com/acme/B.class", data: []byte("b")},
+		}, ArchiveLimits{MaxEntries: 1}, LimitEntries, 0},
+		{"解压总大小超限", []zipEntry{
+			{name: "com/acme/A.class", data: []byte("aaaa")},
+			{name: "com/acme/B.class", data: []byte("bbbb")},
+		}, ArchiveLimits{MaxTotalBytes: 6}, LimitSize, 1},
+		{"压缩比超限", []zipEntry{
+			{name: "com/acme/Bomb.class", data: bomb, method: zip.Deflate},
+		}, ArchiveLimits{MaxRatio: 100}, LimitRatio, 0},
+		{"嵌套层级超限", []zipEntry{
+			{name: "BOOT-INF/lib/middle.jar", data: middle},
+		}, ArchiveLimits{MaxDepth: 1}, LimitDepth, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jarPath := filepath.Join(t.TempDir(), "app.jar")
+			if err := os.WriteFile(jarPath, buildZip(t, tt.entries), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			filterConfig := &FilterConfig{Limits: tt.limits}
+			decompiler := &recordingDecompiler{}
+			outputDir := t.TempDir()
+			rpt := report.New(jarPath, outputDir)
+			if err := NewJarProcessor(decompiler, 1, filterConfig, RunContext{}).Process(context.Background(), jarPath, outputDir, rpt); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if len(rpt.Warnings) != 1 || rpt.Warnings[0].Kind != tt.kind {
+				t.Errorf("Warnings = %+v, want one %s warning", rpt.Warnings, tt.kind)
+			}
+			if len(decompiler.classes) != tt.classes {
+				t.Errorf("反编译了 %d 个 class, want %d", len(decompiler.classes), tt.classes)
+			}
+		})
+	}
+}
+
+func TestWarProcessorMavenLayout(t *testing.T) {
+	war := buildZip(t, []zipEntry{
+		{name: "META-INF/MANIFEST.MF", data: []byte("Manifest-Version: 1.0\n")},
+		{name: "META-INF/maven/com.acme/shop/pom.properties", data: []byte("version=1\n")},
+		{name: "WEB-INF/web.xml", data: []byte("<web-app/>")},
+		{name: "WEB-INF/views/index.jsp", data: []byte("<html/>")},
+		{name: "WEB-INF/classes/com/acme/Shop.class", data: []byte("class")},
+		{name: "WEB-INF/classes/application.properties", data: []byte("a=b\n")},
+		{name: "WEB-INF/lib/acme.jar", data: buildZip(t, nil)},
+		{name: "static/app.js", data: []byte("//")},
+	})
+	warPath := filepath.Join(t.TempDir(), "shop.war")
+	if err := os.WriteFile(warPath, war, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src", "main", "java")
+	// 未指定 -r 时不设置配置文件目录
+	run := RunContext{WebappDir: filepath.Join(root, "src", "main", "webapp")}
+
+	rpt := report.New(warPath, root)
+	if err := NewWarProcessor(&recordingDecompiler{}, 1, NewDefaultFilterConfig(), run).Process(context.Background(), warPath, srcDir, rpt); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		exists bool
+	}{
+		{"配置文件需要 -r", "src/main/resources/application.properties", false},
+		{"web.xml", "src/main/webapp/WEB-INF/web.xml", true},
+		{"JSP", "src/main/webapp/WEB-INF/views/index.jsp", true},
+		{"静态资源", "src/main/webapp/static/app.js", true},
+		{"编译输出", "src/main/webapp/WEB-INF/classes", false},
+		{"依赖 JAR", "src/main/webapp/WEB-INF/lib", false},
+		{"MANIFEST", "src/main/webapp/META-INF/MANIFEST.MF", false},
+		{"Maven 元数据", "src/main/webapp/META-INF/maven", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := os.Stat(filepath.Join(root, filepath.FromSlash(tt.path)))
+			if exists := err == nil; exists != tt.exists {
+				t.Errorf("%s exists = %v, want %v", tt.path, exists, tt.exists)
+			}
+		})
+	}
+}
+
+func TestDirectoryProcessorWebapp(t *testing.T) {
+	input := t.TempDir()
+	for name, data := range map[string]string{
+		"META-INF/MANIFEST.MF":                "Manifest-Version: 1.0\n",
+		"WEB-INF/web.xml":                     "<web-app/>",
+		"WEB-INF/views/index.jsp":             "<html/>",
+		"WEB-INF/classes/com/acme/Shop.class": "class",
+		"WEB-INF/lib/acme.jar":                string(buildZip(t, nil)),
+		"static/app.js":                       "//",
+		"out/reports/report.json":             "{}",
+	} {
+		filePath := filepath.Join(input, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err := os.WriteFile(filePath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 输出目录位于输入目录中，不应被当作 web 内容复制
+	root := filepath.Join(input, "out")
+	run := RunContext{WebappDir: filepath.Join(root, "src", "main", "webapp")}
+
+	rpt := report.New(input, root)
+	if err := NewDirectoryProcessor(&recordingDecompiler{}, 1, NewDefaultFilterConfig(), run).Process(context.Background(), input, filepath.Join(root, "src", "main", "java"), rpt); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		exists bool
+	}{
+		{"web.xml", "WEB-INF/web.xml", true},
+		{"JSP", "WEB-INF/views/index.jsp", true},
+		{"静态资源", "static/app.js", true},
+		{"编译输出", "WEB-INF/classes", false},
+		{"依赖 JAR", "WEB-INF/lib", false},
+		{"MANIFEST", "META-INF/MANIFEST.MF", false},
+		{"输出目录", "out", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := os.Stat(filepath.Join(run.WebappDir, filepath.FromSlash(tt.path)))
+			if exists := err == nil; exists != tt.exists {
+				t.Errorf("%s exists = %v, want %v", tt.path, exists, tt.exists)
+			}
+		})
+	}
+}
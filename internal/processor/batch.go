@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/engine"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// processClassBatches 将 class 组按批次交给 CFR，每个批次只启动一个 JVM
+// baseDir 用于推算每个 class 对应的包路径，以便核对输出文件
+func processClassBatches(ctx context.Context, decompiler engine.Decompiler, groups []ClassGroup, baseDir, outputDir string, workers int, filterConfig *FilterConfig, c *cache.Cache, rpt *report.Report) {
+	batchSize := filterConfig.BatchSize
+	jobs := make(chan []ClassGroup, len(groups)/batchSize+1)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				processBatch(ctx, decompiler, batch, baseDir, outputDir, filterConfig.ClassTimeout, c, rpt)
+			}
+		}()
+	}
+
+	for start := 0; start < len(groups); start += batchSize {
+		end := start + batchSize
+		if end > len(groups) {
+			end = len(groups)
+		}
+		jobs <- groups[start:end]
+	}
+	close(jobs)
+
+	wg.Wait()
+}
+
+// processBatch 反编译一个批次并为其中每个源文件记录结果
+// 批次的超时时间为单个 class 超时乘以批次大小
+func processBatch(ctx context.Context, decompiler engine.Decompiler, groups []ClassGroup, baseDir, outputDir string, classTimeout time.Duration, c *cache.Cache, rpt *report.Report) {
+	out := rpt.Console()
+	startTime := time.Now()
+
+	// 命中缓存的源文件直接记录结果，其余交给反编译器
+	var keys []string
+	pending := groups[:0:0]
+	for _, group := range groups {
+		key, entry := lookupCache(c, group, outputDir)
+		if entry == nil {
+			pending = append(pending, group)
+			keys = append(keys, key)
+			continue
+		}
+		out.Green(out.T("✓ %s (缓存)"), filepath.Base(group.Path))
+		packageName, javaVersion := classInfo(group.Path)
+		rpt.AddResult(report.Result{
+			ClassName:    filepath.Base(group.Path),
+			PackageName:  packageName,
+			JavaVersion:  javaVersion,
+			Path:         group.Location,
+			Success:      true,
+			Cached:       true,
+			Engine:       entry.Engine,
+			InnerClasses: len(group.Inners),
+			TimeTaken:    time.Since(startTime).Seconds(),
+			TimeStamp:    startTime,
+		})
+	}
+	if len(pending) == 0 {
+		return
+	}
+	groups = pending
+	startTime = time.Now()
+
+	batchCtx := ctx
+	if classTimeout > 0 {
+		var cancel context.CancelFunc
+		batchCtx, cancel = context.WithTimeout(ctx, classTimeout*time.Duration(len(groups)))
+		defer cancel()
+	}
+
+	items := make([]engine.BatchItem, len(groups))
+	for i, group := range groups {
+		classPath := group.Path
+		items[i] = engine.BatchItem{
+			InputPath:  classPath,
+			SourcePath: filepath.FromSlash(classSourcePath(classPath, baseDir)),
+		}
+	}
+
+	engineNames, errs := engine.DecompileBatchWithEngine(batchCtx, decompiler, items, outputDir)
+
+	// 批次内无法区分单个 class 的耗时，按平均值记录
+	timeTaken := time.Since(startTime).Seconds() / float64(len(groups))
+	for i, group := range groups {
+		// 整体运行被中断时，未完成的 class 不计入报告
+		if errs[i] != nil && ctx.Err() != nil {
+			continue
+		}
+		packageName, javaVersion := classInfo(group.Path)
+		result := report.Result{
+			ClassName:    filepath.Base(group.Path),
+			PackageName:  packageName,
+			JavaVersion:  javaVersion,
+			Path:         group.Location,
+			Success:      errs[i] == nil,
+			Engine:       engineNames[i],
+			InnerClasses: len(group.Inners),
+			TimeTaken:    timeTaken,
+			TimeStamp:    startTime,
+		}
+		if errors.Is(errs[i], context.DeadlineExceeded) {
+			result.Error = out.T("反编译超时: 批次处理超时")
+			out.Red(out.T("✗ %s (超时)"), result.ClassName)
+		} else if errs[i] != nil {
+			result.Error = out.T("反编译失败: ") + errs[i].Error()
+			out.Red("✗ %s", result.ClassName)
+		} else {
+			out.Green("✓ %s", result.ClassName)
+			storeCache(c, keys[i], group, outputDir, engineNames[i])
+		}
+		rpt.AddResult(result)
+	}
+}
+
+// SourcePathForClass 根据 class 的包路径推算 CFR 生成的 .java 路径
+// 内部类（Foo$Bar.class）会输出到外部类的源文件 Foo.java 中
+func SourcePathForClass(classPath string) string {
+	dir, name := path.Split(filepath.ToSlash(classPath))
+	name = strings.TrimSuffix(name, ".class")
+	if idx := strings.Index(name, "$"); idx > 0 {
+		name = name[:idx]
+	}
+	return dir + name + ".java"
+}
@@ -0,0 +1,22 @@
+package processor
+
+import "testing"
+
+func TestSourcePathForClass(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"com/acme/Foo.class", "com/acme/Foo.java"},
+		{"com/acme/Foo$Bar.class", "com/acme/Foo.java"},
+		{"com/acme/Foo$1.class", "com/acme/Foo.java"},
+		{"Main.class", "Main.java"},
+		{"com/acme/$Proxy.class", "com/acme/$Proxy.java"},
+	}
+
+	for _, tt := range tests {
+		if result := SourcePathForClass(tt.input); result != tt.expected {
+			t.Errorf("SourcePathForClass(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/classfile"
+)
+
+// lookupCache 查找 class 组的缓存结果，命中时直接写入输出目录
+// 返回的 key 用于反编译后保存结果；未启用缓存或无法计算时为空
+func lookupCache(c *cache.Cache, group ClassGroup, outputDir string) (key string, entry *cache.Entry) {
+	if c == nil {
+		return "", nil
+	}
+	key, err := c.Key(append([]string{group.Path}, group.Inners...))
+	if err != nil {
+		return "", nil
+	}
+
+	entry, ok := c.Get(key)
+	if !ok {
+		return key, nil
+	}
+	destPath, err := entryPath(outputDir, entry.SourcePath)
+	if err != nil {
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return key, nil
+	}
+	if err := os.WriteFile(destPath, []byte(entry.Source), 0644); err != nil {
+		return key, nil
+	}
+	return key, entry
+}
+
+// storeCache 保存刚反编译生成的源文件，class 无法解析或源文件不存在时跳过
+func storeCache(c *cache.Cache, key string, group ClassGroup, outputDir, engineName string) {
+	if c == nil || key == "" {
+		return
+	}
+	cf, err := classfile.ParseFile(group.Path)
+	if err != nil {
+		return
+	}
+	sourcePath := SourcePathForClass(cf.ThisClass + ".class")
+	content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(sourcePath)))
+	if err != nil {
+		return
+	}
+	c.Put(key, &cache.Entry{SourcePath: sourcePath, Engine: engineName, Source: string(content)})
+}
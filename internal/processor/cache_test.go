@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// minimalClass 生成只包含类名和父类的 class 文件
+func minimalClass(name string) []byte {
+	var b bytes.Buffer
+	w := func(v any) { binary.Write(&b, binary.BigEndian, v) }
+	utf8 := func(s string) {
+		w(uint8(1))
+		w(uint16(len(s)))
+		b.WriteString(s)
+	}
+
+	w(uint32(0xCAFEBABE))
+	w(uint16(0))
+	w(uint16(52))
+	w(uint16(5))
+	utf8(name)
+	w(uint8(7))
+	w(uint16(1))
+	utf8("java/lang/Object")
+	w(uint8(7))
+	w(uint16(3))
+	for _, v := range []uint16{0x21, 2, 4, 0, 0, 0, 0} {
+		w(v)
+	}
+	return b.Bytes()
+}
+
+// sourceDecompiler 按类名写出 .java 文件并统计调用次数的测试引擎
+type sourceDecompiler struct {
+	calls int
+}
+
+func (d *sourceDecompiler) Name() string                { return "source" }
+func (d *sourceDecompiler) GetVersion() (string, error) { return "", nil }
+
+func (d *sourceDecompiler) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	d.calls++
+	destPath := filepath.Join(outputDir, "com", "acme", "Foo.java")
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(destPath, []byte("class Foo {}"), 0644)
+}
+
+func TestClassProcessorCache(t *testing.T) {
+	classPath := filepath.Join(t.TempDir(), "Foo.class")
+	if err := os.WriteFile(classPath, minimalClass("com/acme/Foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := cache.New(t.TempDir(), "engine=source")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decompiler := &sourceDecompiler{}
+	for run := 0; run < 2; run++ {
+		outputDir := t.TempDir()
+		rpt := report.New(classPath, outputDir)
+		proc := NewClassProcessor(decompiler, 0).WithCache(c)
+		if err := proc.Process(context.Background(), classPath, outputDir, rpt); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		result := rpt.Results[0]
+		if !result.Success || result.Cached != (run == 1) || result.PackageName != "com/acme" {
+			t.Errorf("第 %d 次运行 result = %+v", run+1, result)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, "com", "acme", "Foo.java")); err != nil {
+			t.Errorf("第 %d 次运行未生成源文件: %v", run+1, err)
+		}
+	}
+	if decompiler.calls != 1 {
+		t.Errorf("反编译器调用 %d 次, want 1", decompiler.calls)
+	}
+}
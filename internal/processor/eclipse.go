@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// GenerateEclipseProject 在输出目录生成 Eclipse 的 .project、.classpath 和 .settings
+// 依赖库目录中有同名的 -sources.jar 时作为 lib JAR 的源码附件
+func GenerateEclipseProject(config *ProjectConfig) error {
+	jars, err := libJars(config)
+	if err != nil {
+		return fmt.Errorf(i18n.T("读取依赖 JAR 失败: %v"), err)
+	}
+
+	project := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<projectDescription>
+	<name>%s</name>
+	<comment></comment>
+	<projects>
+	</projects>
+	<buildSpec>
+		<buildCommand>
+			<name>org.eclipse.jdt.core.javabuilder</name>
+			<arguments>
+			</arguments>
+		</buildCommand>
+	</buildSpec>
+	<natures>
+		<nature>org.eclipse.jdt.core.javanature</nature>
+	</natures>
+</projectDescription>
+`, xmlText(config.ProjectName))
+
+	version := config.javaVersion()
+	srcDir := projectPath(config, config.SrcDir)
+	var classpath strings.Builder
+	classpath.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<classpath>\n")
+	fmt.Fprintf(&classpath, "\t<classpathentry kind=\"src\" path=\"%s\"/>\n", xmlText(srcDir))
+	if config.ResourcesDir != "" {
+		if _, err := os.Stat(config.ResourcesDir); err == nil {
+			fmt.Fprintf(&classpath, "\t<classpathentry kind=\"src\" path=\"%s\" excluding=\"**/*.java\"/>\n", xmlText(projectPath(config, config.ResourcesDir)))
+		}
+	}
+	fmt.Fprintf(&classpath, "\t<classpathentry kind=\"con\" path=\"org.eclipse.jdt.launching.JRE_CONTAINER/org.eclipse.jdt.internal.debug.ui.launcher.StandardVMType/%s\"/>\n", eclipseExecutionEnvironment(version))
+	for _, jar := range jars {
+		if jar.sources != "" {
+			fmt.Fprintf(&classpath, "\t<classpathentry kind=\"lib\" path=\"%s\" sourcepath=\"%s\"/>\n", xmlText(jar.path), xmlText(jar.sources))
+		} else {
+			fmt.Fprintf(&classpath, "\t<classpathentry kind=\"lib\" path=\"%s\"/>\n", xmlText(jar.path))
+		}
+	}
+	classpath.WriteString("\t<classpathentry kind=\"output\" path=\"bin\"/>\n</classpath>\n")
+
+	// 编译器级别与 IDEA、Maven 使用相同的 Java 版本
+	release := javaRelease(version)
+	prefs := fmt.Sprintf(`eclipse.preferences.version=1
+org.eclipse.jdt.core.compiler.codegen.targetPlatform=%s
+org.eclipse.jdt.core.compiler.compliance=%s
+org.eclipse.jdt.core.compiler.source=%s
+`, release, release, release)
+
+	settingsDir := filepath.Join(config.OutputDir, ".settings")
+	if err := os.MkdirAll(settingsDir, 0755); err != nil {
+		return fmt.Errorf(i18n.T("创建 .settings 目录失败: %v"), err)
+	}
+	files := []struct {
+		path    string
+		content string
+	}{
+		{filepath.Join(config.OutputDir, ".project"), project},
+		{filepath.Join(config.OutputDir, ".classpath"), classpath.String()},
+		{filepath.Join(settingsDir, "org.eclipse.jdt.core.prefs"), prefs},
+		{filepath.Join(settingsDir, "org.eclipse.core.resources.prefs"), "eclipse.preferences.version=1\nencoding/<project>=UTF-8\n"},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eclipseExecutionEnvironment 返回 Eclipse 的执行环境名称，如 JavaSE-1.8、JavaSE-17
+func eclipseExecutionEnvironment(version int) string {
+	switch {
+	case version <= 5:
+		return fmt.Sprintf("J2SE-1.%d", version)
+	case version <= 8:
+		return fmt.Sprintf("JavaSE-1.%d", version)
+	default:
+		return fmt.Sprintf("JavaSE-%d", version)
+	}
+}
+
+// libJar 依赖库目录中的 JAR 及其源码 JAR，路径相对于输出根目录
+type libJar struct {
+	path    string
+	sources string // 同目录下的 <name>-sources.jar，不存在时为空
+}
+
+// libJars 返回依赖库目录中的 JAR，按文件名排序，-sources.jar 作为对应 JAR 的源码而不单独列出
+func libJars(config *ProjectConfig) ([]libJar, error) {
+	if config.LibsDir == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(config.LibsDir, "*.jar"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file] = true
+	}
+	var jars []libJar
+	for _, file := range files {
+		if strings.HasSuffix(file, "-sources.jar") {
+			continue
+		}
+		jar := libJar{path: projectPath(config, file)}
+		if sources := strings.TrimSuffix(file, ".jar") + "-sources.jar"; present[sources] {
+			jar.sources = projectPath(config, sources)
+		}
+		jars = append(jars, jar)
+	}
+	return jars, nil
+}
@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateEclipseProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		res     bool
+		want    []string
+		notWant []string
+	}{
+		{"Java 8 带配置文件", 8, true, []string{
+			`<classpathentry kind="src" path="src/main/java"/>`,
+			`<classpathentry kind="src" path="src/main/resources" excluding="**/*.java"/>`,
+			`StandardVMType/JavaSE-1.8"/>`,
+			`<classpathentry kind="lib" path="libs/a.jar" sourcepath="libs/a-sources.jar"/>`,
+			`<classpathentry kind="lib" path="libs/b.jar"/>`,
+		}, []string{`kind="lib" path="libs/a-sources.jar"`}},
+		{"Java 17 没有配置文件目录", 17, false, []string{
+			`StandardVMType/JavaSE-17"/>`,
+		}, []string{"src/main/resources"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := &ProjectConfig{
+				ProjectName:  "demo",
+				OutputDir:    dir,
+				SrcDir:       filepath.Join(dir, "src", "main", "java"),
+				ResourcesDir: filepath.Join(dir, "src", "main", "resources"),
+				LibsDir:      filepath.Join(dir, "libs"),
+				JavaVersion:  tt.version,
+			}
+			os.MkdirAll(config.LibsDir, 0755)
+			os.WriteFile(filepath.Join(config.LibsDir, "b.jar"), buildZip(t, nil), 0644)
+			os.WriteFile(filepath.Join(config.LibsDir, "a.jar"), buildZip(t, nil), 0644)
+			os.WriteFile(filepath.Join(config.LibsDir, "a-sources.jar"), buildZip(t, nil), 0644)
+			if tt.res {
+				os.MkdirAll(config.ResourcesDir, 0755)
+			}
+
+			if err := GenerateEclipseProject(config); err != nil {
+				t.Fatal(err)
+			}
+			project, err := os.ReadFile(filepath.Join(dir, ".project"))
+			if err != nil || !strings.Contains(string(project), "<name>demo</name>") {
+				t.Errorf(".project = %q, %v", project, err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, ".classpath"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf(".classpath missing %q:\n%s", want, data)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(data), notWant) {
+					t.Errorf(".classpath contains %q", notWant)
+				}
+			}
+			if strings.Index(string(data), "libs/a.jar") > strings.Index(string(data), "libs/b.jar") {
+				t.Errorf("lib entries not sorted:\n%s", data)
+			}
+		})
+	}
+}
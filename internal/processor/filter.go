@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// classPattern 编译后的 class 过滤规则
+// 规则写法：
+//   - com/acme/            包前缀
+//   - com/acme/**/dto/*    Ant 风格通配符，** 匹配任意层目录，* 和 ? 不跨越 /
+//   - re:.*Proto\.class$   正则表达式
+//   - !规则                 取反，撤销之前规则的匹配结果
+//
+// 所有规则都作用于以 / 分隔、带 .class 后缀的相对路径，如 com/acme/dto/User.class
+type classPattern struct {
+	negate bool
+	prefix string         // 包前缀规则
+	re     *regexp.Regexp // 通配符或正则规则
+}
+
+// compileClassPattern 编译单条过滤规则
+func compileClassPattern(pattern string) (classPattern, error) {
+	var p classPattern
+	if strings.HasPrefix(pattern, "!") {
+		p.negate = true
+		pattern = pattern[1:]
+	}
+
+	switch {
+	case strings.HasPrefix(pattern, "re:"):
+		re, err := regexp.Compile(pattern[len("re:"):])
+		if err != nil {
+			return p, fmt.Errorf(i18n.T("无效的正则过滤规则 %q: %v"), pattern, err)
+		}
+		p.re = re
+	case strings.ContainsAny(pattern, "*?"):
+		p.re = regexp.MustCompile(globToRegexp(pattern))
+	case pattern == "":
+		return p, fmt.Errorf(i18n.T("过滤规则不能为空"))
+	default:
+		p.prefix = pattern
+	}
+	return p, nil
+}
+
+// globToRegexp 将 Ant 风格通配符转换为正则表达式
+// 以 / 结尾的规则等同于追加 **，匹配目录下的所有文件；
+// 不以 .class 结尾的规则可以省略路径的 .class 后缀，如 com/acme/*Dto 匹配 com/acme/UserDto.class
+func globToRegexp(glob string) string {
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if !strings.HasSuffix(glob, ".class") && !strings.HasSuffix(glob, "**") {
+		sb.WriteString(`(?:\.class)?`)
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+func (p classPattern) match(relativePath string) bool {
+	if p.re != nil {
+		return p.re.MatchString(relativePath)
+	}
+	return strings.HasPrefix(relativePath, p.prefix)
+}
+
+// compileClassPatterns 编译一组过滤规则
+func compileClassPatterns(patterns []string) ([]classPattern, error) {
+	compiled := make([]classPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := compileClassPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+// matchClassPatterns 按顺序应用规则，最后一条匹配的规则决定结果
+// 取反规则只能撤销前面规则的匹配，单独使用时不匹配任何路径
+func matchClassPatterns(patterns []classPattern, relativePath string) bool {
+	matched := false
+	for _, p := range patterns {
+		if p.match(relativePath) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
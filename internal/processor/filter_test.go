@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestShouldProcessClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		path     string
+		expected bool
+	}{
+		{"无规则时全部处理", nil, nil, "com/acme/Foo.class", true},
+		{"包前缀包含", []string{"com/acme/"}, nil, "com/acme/Foo.class", true},
+		{"包前缀不匹配", []string{"com/acme/"}, nil, "org/other/Foo.class", false},
+		{"包含范围内排除", []string{"com/acme/"}, []string{"com/acme/proto/"}, "com/acme/proto/User.class", false},
+		{"包含范围内未排除", []string{"com/acme/"}, []string{"com/acme/proto/"}, "com/acme/api/User.class", true},
+		{"通配符跨目录", []string{"com/acme/**/dto/*"}, nil, "com/acme/order/v1/dto/Order.class", true},
+		{"通配符零层目录", []string{"com/acme/**/dto/*"}, nil, "com/acme/dto/Order.class", true},
+		{"单星号不跨目录", []string{"com/acme/**/dto/*"}, nil, "com/acme/dto/inner/Order.class", false},
+		{"问号匹配单个字符", []string{"com/acme/V?/*"}, nil, "com/acme/V2/Foo.class", true},
+		{"正则排除", []string{"com/acme/"}, []string{`re:.*Proto(\$.*)?\.class$`}, "com/acme/UserProto$Builder.class", false},
+		{"取反包含", []string{"com/acme/", "!com/acme/internal/"}, nil, "com/acme/internal/Foo.class", false},
+		{"取反排除", nil, []string{"com/acme/gen/", "!com/acme/gen/Keep*"}, "com/acme/gen/KeepMe.class", true},
+		{"取反排除之外仍排除", nil, []string{"com/acme/gen/", "!com/acme/gen/Keep*"}, "com/acme/gen/Other.class", false},
+		{"通配符省略 .class", []string{"com/acme/*Dto"}, nil, "com/acme/UserDto.class", true},
+		{"通配符省略 .class 不匹配其他后缀", []string{"com/acme/*Dto"}, nil, "com/acme/UserDtoImpl.class", false},
+		{"任意包中的类名", []string{"com/acme/"}, []string{"**/*Proto.class"}, "com/acme/api/UserProto.class", false},
+		{"任意包中的类名不匹配其他类", []string{"com/acme/"}, []string{"**/*Proto.class"}, "com/acme/api/User.class", true},
+	}
+
+	baseDir := filepath.FromSlash("/tmp/emorad")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &FilterConfig{Includes: tt.includes, Excludes: tt.excludes}
+			if err := f.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			classPath := filepath.Join(baseDir, filepath.FromSlash(tt.path))
+			if result := f.ShouldProcessClass(classPath, baseDir); result != tt.expected {
+				t.Errorf("ShouldProcessClass(%q) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidRegexp(t *testing.T) {
+	f := &FilterConfig{Excludes: []string{"re:com/(acme"}}
+	if err := f.Validate(); err == nil {
+		t.Error("Validate() 应拒绝无效的正则表达式")
+	}
+}
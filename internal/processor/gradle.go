@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// Gradle 构建文件的 DSL
+const (
+	GradleGroovy = "groovy" // settings.gradle、build.gradle
+	GradleKotlin = "kotlin" // settings.gradle.kts、build.gradle.kts
+)
+
+// GenerateGradleProject 在输出目录生成 settings.gradle 和 build.gradle（kotlin 时为 .kts）
+// 源代码和配置文件使用 Gradle 的默认目录 src/main/java、src/main/resources；
+// 依赖取自 libs 目录中各 JAR 的 pom.properties，无法确定坐标的 JAR 以 files('libs/...') 引用
+func GenerateGradleProject(config *ProjectConfig, dsl string) ([]Dependency, error) {
+	if dsl != GradleGroovy && dsl != GradleKotlin {
+		return nil, fmt.Errorf(i18n.T("不支持的 Gradle DSL %q，可选 groovy、kotlin"), dsl)
+	}
+	deps, err := ResolveDependencies(config.LibsDir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("读取依赖 JAR 失败: %v"), err)
+	}
+
+	project := projectCoordinates(config)
+	kotlin := dsl == GradleKotlin
+	quote := func(s string) string {
+		if kotlin {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`).Replace(s) + `"`
+		}
+		return `'` + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + `'`
+	}
+	call := func(name, arg string) string {
+		if kotlin {
+			return name + "(" + arg + ")"
+		}
+		return name + " " + arg
+	}
+
+	settings := "rootProject.name = " + quote(project.ArtifactID) + "\n"
+
+	var b strings.Builder
+	b.WriteString("plugins {\n")
+	if kotlin {
+		b.WriteString("    java\n")
+		if isWar(config.InputPath) {
+			b.WriteString("    war\n")
+		}
+	} else {
+		b.WriteString("    id 'java'\n")
+		if isWar(config.InputPath) {
+			b.WriteString("    id 'war'\n")
+		}
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "group = %s\nversion = %s\n\n", quote(project.GroupID), quote(project.Version))
+	javaVersion := gradleJavaVersion(config.javaVersion())
+	fmt.Fprintf(&b, `java {
+    sourceCompatibility = JavaVersion.%s
+    targetCompatibility = JavaVersion.%s
+}
+
+`, javaVersion, javaVersion)
+	if kotlin {
+		b.WriteString("tasks.withType<JavaCompile>().configureEach {\n    options.encoding = \"UTF-8\"\n}\n\n")
+	} else {
+		b.WriteString("tasks.withType(JavaCompile).configureEach {\n    options.encoding = 'UTF-8'\n}\n\n")
+	}
+	b.WriteString("repositories {\n    mavenCentral()\n}\n")
+
+	if len(deps) > 0 {
+		libsDir := projectPath(config, config.LibsDir)
+		b.WriteString("\ndependencies {\n")
+		for _, dep := range deps {
+			if dep.Resolved {
+				fmt.Fprintf(&b, "    %s\n", call("implementation", quote(dep.Coordinate())))
+			} else {
+				fmt.Fprintf(&b, "    %s\n", call("implementation", "files("+quote(libsDir+"/"+dep.File)+")"))
+			}
+		}
+		b.WriteString("}\n")
+	}
+
+	settingsFile, buildFile := "settings.gradle", "build.gradle"
+	if kotlin {
+		settingsFile, buildFile = "settings.gradle.kts", "build.gradle.kts"
+	}
+	if err := os.WriteFile(filepath.Join(config.OutputDir, settingsFile), []byte(settings), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(config.OutputDir, buildFile), []byte(b.String()), 0644); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGradleProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsl      string
+		settings string
+		build    string
+		want     []string
+	}{
+		{"Groovy DSL", GradleGroovy, "settings.gradle", "build.gradle", []string{
+			"id 'java'\n    id 'war'",
+			"group = 'com.acme'\nversion = '2.1.0'",
+			"sourceCompatibility = JavaVersion.VERSION_1_8",
+			"implementation 'com.google.code.gson:gson:2.10'",
+			"implementation files('libs/vendor.jar')",
+		}},
+		{"Kotlin DSL", GradleKotlin, "settings.gradle.kts", "build.gradle.kts", []string{
+			"    java\n    war\n",
+			"group = \"com.acme\"\nversion = \"2.1.0\"",
+			"implementation(\"com.google.code.gson:gson:2.10\")",
+			"implementation(files(\"libs/vendor.jar\"))",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "shop.war")
+			os.WriteFile(input, buildZip(t, []zipEntry{pomProperties("com.acme", "shop", "2.1.0")}), 0644)
+
+			output := filepath.Join(dir, "out")
+			config := &ProjectConfig{
+				ProjectName:  "out",
+				InputPath:    input,
+				OutputDir:    output,
+				SrcDir:       filepath.Join(output, "src", "main", "java"),
+				ResourcesDir: filepath.Join(output, "src", "main", "resources"),
+				LibsDir:      filepath.Join(output, "libs"),
+				JavaVersion:  8,
+			}
+			os.MkdirAll(config.LibsDir, 0755)
+			os.WriteFile(filepath.Join(config.LibsDir, "gson-2.10.jar"), buildZip(t, []zipEntry{pomProperties("com.google.code.gson", "gson", "2.10")}), 0644)
+			os.WriteFile(filepath.Join(config.LibsDir, "vendor.jar"), buildZip(t, nil), 0644)
+
+			if _, err := GenerateGradleProject(config, tt.dsl); err != nil {
+				t.Fatal(err)
+			}
+			settings, err := os.ReadFile(filepath.Join(output, tt.settings))
+			if err != nil || !strings.Contains(string(settings), "rootProject.name = ") || !strings.Contains(string(settings), "shop") {
+				t.Errorf("%s = %q, %v", tt.settings, settings, err)
+			}
+			data, err := os.ReadFile(filepath.Join(output, tt.build))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("%s missing %q:\n%s", tt.build, want, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ClassGroup 编译自同一个源文件的 class：顶层类及其内部类、匿名类和 lambda 类
+// 反编译器处理顶层类时会一并输出内部类，因此每组只需反编译一次
+type ClassGroup struct {
+	Path   string   // 交给反编译器的 class 文件，通常是顶层类
+	Inners []string // 同一源文件中的其他 class
+
+	// Location 顶层类在输入中的位置，写入报告用于恢复运行，
+	// 如 app.jar!/BOOT-INF/classes/com/acme/Foo.class
+	Location string
+}
+
+// GroupClasses 按源文件对 class 分组，保持各组首次出现的顺序
+// 缺少顶层类时（如只有 Foo$1.class），以组内第一个 class 代替
+func GroupClasses(classFiles []string) []ClassGroup {
+	var groups []ClassGroup
+	index := make(map[string]int, len(classFiles))
+
+	for _, classPath := range classFiles {
+		key := SourcePathForClass(classPath)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(groups)
+			groups = append(groups, ClassGroup{Path: classPath})
+			continue
+		}
+
+		group := &groups[i]
+		if isOuterClass(classPath) && !isOuterClass(group.Path) {
+			group.Inners = append(group.Inners, group.Path)
+			group.Path = classPath
+		} else {
+			group.Inners = append(group.Inners, classPath)
+		}
+	}
+	return groups
+}
+
+// isOuterClass 判断 class 是否为源文件中的顶层类
+func isOuterClass(classPath string) bool {
+	name := strings.TrimSuffix(filepath.Base(filepath.FromSlash(classPath)), ".class")
+	return strings.Index(name, "$") <= 0
+}
@@ -0,0 +1,30 @@
+package processor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupClasses(t *testing.T) {
+	input := []string{
+		"com/acme/Foo$1.class",
+		"com/acme/Bar.class",
+		"com/acme/Foo.class",
+		"com/acme/Foo$Inner.class",
+		"com/acme/Foo$$Lambda$1.class",
+		"com/acme/Orphan$1.class",
+		"com/acme/$Proxy.class",
+		"com/other/Foo.class",
+	}
+	expected := []ClassGroup{
+		{Path: "com/acme/Foo.class", Inners: []string{"com/acme/Foo$1.class", "com/acme/Foo$Inner.class", "com/acme/Foo$$Lambda$1.class"}},
+		{Path: "com/acme/Bar.class"},
+		{Path: "com/acme/Orphan$1.class"},
+		{Path: "com/acme/$Proxy.class"},
+		{Path: "com/other/Foo.class"},
+	}
+
+	if result := GroupClasses(input); !reflect.DeepEqual(result, expected) {
+		t.Errorf("GroupClasses() = %+v, want %+v", result, expected)
+	}
+}
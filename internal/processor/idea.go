@@ -1,25 +1,51 @@
 package processor
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// 输出目录结构
+const (
+	LayoutFlat  = "flat"  // 源代码位于输出目录（生成项目时为 src），配置文件位于 resources
+	LayoutMaven = "maven" // src/main/java、src/main/resources，WAR 的 web 内容位于 src/main/webapp
 )
 
-// ProjectConfig IDEA 项目生成配置
+// ProjectConfig 项目配置文件（IDEA、Maven、Gradle）的生成配置
 type ProjectConfig struct {
-	ProjectName string // 项目名称
-	OutputDir   string // 输出根目录
-	SrcDir      string // 源代码目录
-	LibsDir     string // 依赖库目录
+	ProjectName  string // 项目名称
+	InputPath    string // 反编译的输入，用于读取项目自身的 Maven 坐标
+	OutputDir    string // 输出根目录
+	SrcDir       string // 源代码目录
+	ResourcesDir string // 配置文件目录
+	WebappDir    string // WAR 的 web 内容目录，为空表示没有
+	LibsDir      string // 依赖库目录
+	JavaVersion  int    // 项目的 Java 版本，如 8、17，0 表示 DefaultJavaVersion
+}
+
+// javaVersion 返回项目的 Java 版本
+func (c *ProjectConfig) javaVersion() int {
+	if c.JavaVersion > 0 {
+		return c.JavaVersion
+	}
+	return DefaultJavaVersion
 }
 
 // GenerateIDEAProject 生成完整的 IDEA 项目结构
 func GenerateIDEAProject(config *ProjectConfig) error {
 	ideaDir := filepath.Join(config.OutputDir, ".idea")
 	if err := os.MkdirAll(ideaDir, 0755); err != nil {
-		return fmt.Errorf("创建 .idea 目录失败: %v", err)
+		return fmt.Errorf(i18n.T("创建 .idea 目录失败: %v"), err)
 	}
 
 	// 生成 .iml 模块文件
@@ -44,7 +70,7 @@ func GenerateIDEAProject(config *ProjectConfig) error {
 func generateIMLFile(config *ProjectConfig) error {
 	// 收集所有 JAR 依赖
 	var jarEntries strings.Builder
-	libsDir := filepath.Join(config.OutputDir, "libs")
+	libsDir := config.LibsDir
 
 	if _, err := os.Stat(libsDir); err == nil {
 		err = filepath.Walk(libsDir, func(path string, info os.FileInfo, err error) error {
@@ -64,13 +90,40 @@ func generateIMLFile(config *ProjectConfig) error {
 		}
 	}
 
+	// 配置文件目录标记为资源目录，避免被当作 Java 包
+	var folders strings.Builder
+	fmt.Fprintf(&folders, `      <sourceFolder url="file://$MODULE_DIR$/%s" isTestSource="false" />
+`, projectPath(config, config.SrcDir))
+	if config.ResourcesDir != "" {
+		fmt.Fprintf(&folders, `      <sourceFolder url="file://$MODULE_DIR$/%s" type="java-resource" />
+`, projectPath(config, config.ResourcesDir))
+	}
+
+	// WAR 的 web 内容目录通过 Web facet 标记为 web 根目录
+	var facet string
+	if config.WebappDir != "" {
+		webapp := projectPath(config, config.WebappDir)
+		facet = fmt.Sprintf(`  <component name="FacetManager">
+    <facet type="web" name="Web">
+      <configuration>
+        <descriptors>
+          <deploymentDescriptor name="web.xml" url="file://$MODULE_DIR$/%s/WEB-INF/web.xml" />
+        </descriptors>
+        <webroots>
+          <root url="file://$MODULE_DIR$/%s" relative="/" />
+        </webroots>
+      </configuration>
+    </facet>
+  </component>
+`, webapp, webapp)
+	}
+
 	imlContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <module type="JAVA_MODULE" version="4">
-  <component name="NewModuleRootManager" inherit-compiler-output="true">
+%s  <component name="NewModuleRootManager" LANGUAGE_LEVEL="%s" inherit-compiler-output="true">
     <exclude-output />
     <content url="file://$MODULE_DIR$">
-      <sourceFolder url="file://$MODULE_DIR$/src" isTestSource="false" />
-      <excludeFolder url="file://$MODULE_DIR$/reports" />
+%s      <excludeFolder url="file://$MODULE_DIR$/reports" />
     </content>
     <orderEntry type="inheritedJdk" />
     <orderEntry type="sourceFolder" forTests="false" />
@@ -84,7 +137,7 @@ func generateIMLFile(config *ProjectConfig) error {
     </orderEntry>
   </component>
 </module>
-`, jarEntries.String())
+`, facet, ideaLanguageLevel(config.javaVersion()), folders.String(), jarEntries.String())
 
 	imlPath := filepath.Join(config.OutputDir, config.ProjectName+".iml")
 	return os.WriteFile(imlPath, []byte(imlContent), 0644)
@@ -108,52 +161,98 @@ func generateModulesXML(config *ProjectConfig) error {
 
 // generateMiscXML 生成 misc.xml (JDK 配置)
 func generateMiscXML(config *ProjectConfig) error {
-	content := `<?xml version="1.0" encoding="UTF-8"?>
+	version := config.javaVersion()
+	content := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <project version="4">
-  <component name="ProjectRootManager" version="2" languageLevel="JDK_11" default="true" project-jdk-name="11" project-jdk-type="JavaSDK">
+  <component name="ProjectRootManager" version="2" languageLevel="%s" default="true" project-jdk-name="%s" project-jdk-type="JavaSDK">
     <output url="file://$PROJECT_DIR$/out" />
   </component>
 </project>
-`
+`, ideaLanguageLevel(version), javaRelease(version))
 	xmlPath := filepath.Join(config.OutputDir, ".idea", "misc.xml")
 	return os.WriteFile(xmlPath, []byte(content), 0644)
 }
 
-// CopyLibJars 复制依赖 JAR 到 libs 目录
-func CopyLibJars(jarPaths []string, outputDir string) (int, error) {
-	libsDir := filepath.Join(outputDir, "libs")
+// CopyLibJars 将压缩包中的依赖 JAR 直接写入 libsDir，返回写入的数量
+// 与 libs 中已有文件内容相同的 JAR 不再写入；不同目录下的同名 JAR 依次命名为 util_2.jar、util_3.jar，
+// 构建文件和项目配置按 libs 中的文件名引用。单个 JAR 失败时继续处理其余的，最后返回全部错误
+func CopyLibJars(jars []*zip.File, libsDir string) (int, error) {
 	if err := os.MkdirAll(libsDir, 0755); err != nil {
-		return 0, fmt.Errorf("创建 libs 目录失败: %v", err)
+		return 0, fmt.Errorf(i18n.T("创建 libs 目录失败: %v"), err)
 	}
 
 	copied := 0
-	for _, jarPath := range jarPaths {
-		jarName := filepath.Base(jarPath)
-		destPath := filepath.Join(libsDir, jarName)
-
-		// 跳过已存在的文件
-		if _, err := os.Stat(destPath); err == nil {
-			continue
+	var errs []error
+	for _, jar := range jars {
+		destPath, exists, err := libJarPath(jar, libsDir)
+		if err == nil && !exists {
+			if err = extractEntry(jar, destPath); err != nil {
+				os.Remove(destPath)
+			} else {
+				copied++
+			}
 		}
-
-		srcFile, err := os.Open(jarPath)
 		if err != nil {
-			continue
+			errs = append(errs, fmt.Errorf("%s: %v", jar.Name, err))
 		}
+	}
 
-		destFile, err := os.Create(destPath)
+	return copied, errors.Join(errs...)
+}
+
+// libJarPath 返回 JAR 在 libsDir 中的路径，exists 表示该路径已有内容相同的文件
+func libJarPath(jar *zip.File, libsDir string) (string, bool, error) {
+	base := path.Base(jar.Name)
+	ext := path.Ext(base)
+	var sum []byte
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(base, ext), n, ext)
+		}
+		destPath := filepath.Join(libsDir, name)
+		info, err := os.Stat(destPath)
+		if os.IsNotExist(err) {
+			return destPath, false, nil
+		}
 		if err != nil {
-			srcFile.Close()
+			return "", false, err
+		}
+		if info.Size() != int64(jar.UncompressedSize64) {
 			continue
 		}
 
-		if _, err := destFile.ReadFrom(srcFile); err == nil {
-			copied++
+		if sum == nil {
+			rc, err := jar.Open()
+			if err != nil {
+				return "", false, err
+			}
+			sum, err = readerSHA256(rc)
+			rc.Close()
+			if err != nil {
+				return "", false, err
+			}
+		}
+		f, err := os.Open(destPath)
+		if err != nil {
+			return "", false, err
+		}
+		existing, err := readerSHA256(f)
+		f.Close()
+		if err != nil {
+			return "", false, err
+		}
+		if bytes.Equal(sum, existing) {
+			return destPath, true, nil
 		}
-
-		srcFile.Close()
-		destFile.Close()
 	}
+}
 
-	return copied, nil
+// readerSHA256 返回 r 中全部内容的 SHA-256
+func readerSHA256(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
 }
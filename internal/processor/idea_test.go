@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIMLFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		res     string
+		webapp  string
+		want    []string
+		notWant []string
+	}{
+		{"flat 结构", "src", "resources", "", []string{
+			`<sourceFolder url="file://$MODULE_DIR$/src" isTestSource="false" />`,
+			`<sourceFolder url="file://$MODULE_DIR$/resources" type="java-resource" />`,
+		}, []string{"FacetManager"}},
+		{"maven 结构", "src/main/java", "src/main/resources", "src/main/webapp", []string{
+			`<sourceFolder url="file://$MODULE_DIR$/src/main/java" isTestSource="false" />`,
+			`<sourceFolder url="file://$MODULE_DIR$/src/main/resources" type="java-resource" />`,
+			`<root url="file://$MODULE_DIR$/src/main/webapp" relative="/" />`,
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := &ProjectConfig{
+				ProjectName:  "demo",
+				OutputDir:    dir,
+				SrcDir:       filepath.Join(dir, filepath.FromSlash(tt.src)),
+				ResourcesDir: filepath.Join(dir, filepath.FromSlash(tt.res)),
+				LibsDir:      filepath.Join(dir, "libs"),
+			}
+			if tt.webapp != "" {
+				config.WebappDir = filepath.Join(dir, filepath.FromSlash(tt.webapp))
+			}
+			if err := generateIMLFile(config); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "demo.iml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("demo.iml missing %q:\n%s", want, data)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(data), notWant) {
+					t.Errorf("demo.iml contains %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyLibJars(t *testing.T) {
+	first := buildZip(t, []zipEntry{{name: "a.txt", data: []byte("first")}})
+	second := buildZip(t, []zipEntry{{name: "b.txt", data: []byte("second")}})
+	outer := buildZip(t, []zipEntry{
+		{name: "BOOT-INF/lib/util.jar", data: first},
+		{name: "WEB-INF/lib/util.jar", data: second},
+		{name: "lib/util.jar", data: first},
+		{name: "lib/broken.jar", data: []byte("CORRUPT")},
+	})
+	// 破坏 broken.jar 的数据，解压时校验和不匹配
+	outer = bytes.Replace(outer, []byte("CORRUPT"), []byte("CORRUPX"), 1)
+	r, err := zip.NewReader(bytes.NewReader(outer), int64(len(outer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	libsDir := t.TempDir()
+	copied, err := CopyLibJars(r.File, libsDir)
+	if copied != 2 {
+		t.Errorf("copied = %d, want 2", copied)
+	}
+	if err == nil || !strings.Contains(err.Error(), "lib/broken.jar") {
+		t.Errorf("err = %v, want error for lib/broken.jar", err)
+	}
+
+	for name, want := range map[string][]byte{"util.jar": first, "util_2.jar": second} {
+		if data, _ := os.ReadFile(filepath.Join(libsDir, name)); !bytes.Equal(data, want) {
+			t.Errorf("%s 内容不正确", name)
+		}
+	}
+	for _, name := range []string{"util_3.jar", "broken.jar"} {
+		if _, err := os.Stat(filepath.Join(libsDir, name)); err == nil {
+			t.Errorf("%s 不应存在", name)
+		}
+	}
+
+	// 再次复制时内容相同的 JAR 不重复写入
+	if copied, _ := CopyLibJars(r.File[:3], libsDir); copied != 0 {
+		t.Errorf("second copy = %d, want 0", copied)
+	}
+}
@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultJavaVersion 无法从 class 文件和 MANIFEST.MF 确定版本时，生成的项目使用的 Java 版本
+const DefaultJavaVersion = 11
+
+// JavaVersionFromClass DetectJavaVersion 的来源：class 文件的编译目标
+const JavaVersionFromClass = "class"
+
+// manifestJDKKeys MANIFEST.MF 中记录构建 JDK 的属性，按优先级排列
+// Build-Jdk-Spec 由 maven-archiver 3.x 写入，如 17；Build-Jdk 由旧版本写入，如 1.8.0_201；
+// Created-By 为 JDK 的 jar 工具或构建工具写入，如 1.8.0_292 (Oracle Corporation)
+var manifestJDKKeys = []string{"Build-Jdk-Spec", "Build-Jdk", "Created-By"}
+
+// DetectJavaVersion 确定生成的项目使用的 Java 版本，返回版本和来源
+// 优先使用应用 class 文件中数量最多的编译目标（classVersions 为各版本的源文件数量，数量相同时取较高的版本），
+// 来源为 JavaVersionFromClass；个别以更高版本编译的类不会抬高整个项目的版本。
+// 没有时使用输入的 META-INF/MANIFEST.MF 中记录的构建 JDK，来源为属性名；都没有时返回 DefaultJavaVersion 和空来源
+func DetectJavaVersion(inputPath string, classVersions map[int]int) (int, string) {
+	dominant, most := 0, 0
+	for version, count := range classVersions {
+		if version <= 0 || count <= 0 {
+			continue
+		}
+		if count > most || count == most && version > dominant {
+			dominant, most = version, count
+		}
+	}
+	if dominant > 0 {
+		return normalizeJavaVersion(dominant), JavaVersionFromClass
+	}
+
+	manifest, err := readManifest(inputPath)
+	if err == nil {
+		for _, key := range manifestJDKKeys {
+			if version := parseJavaVersion(manifest[key]); version > 0 {
+				return version, key
+			}
+		}
+	}
+	return DefaultJavaVersion, ""
+}
+
+// normalizeJavaVersion 将 1.4 及更早的版本（classfile.JavaVersion 返回 1）视为 1.4
+func normalizeJavaVersion(version int) int {
+	if version < 4 {
+		return 4
+	}
+	return version
+}
+
+// parseJavaVersion 解析 JDK 版本字符串，如 17、1.8、1.8.0_292、17.0.2 (Eclipse Adoptium)
+// 不以版本号开头的值（如 Apache Maven 3.6.3）返回 0
+func parseJavaVersion(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	parts := strings.FieldsFunc(fields[0], func(r rune) bool { return r == '.' || r == '_' || r == '-' || r == '+' })
+	if len(parts) == 0 {
+		return 0
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0
+	}
+	if major == 1 && len(parts) > 1 {
+		major, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return 0
+		}
+	}
+	if major < 1 || major > 99 {
+		return 0
+	}
+	return major
+}
+
+// readManifest 读取 JAR、WAR 或目录输入的 META-INF/MANIFEST.MF
+func readManifest(inputPath string) (map[string]string, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		f, err := os.Open(filepath.Join(inputPath, "META-INF", "MANIFEST.MF"))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parseManifest(f)
+	}
+
+	r, err := zip.OpenReader(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "META-INF/MANIFEST.MF" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return parseManifest(rc)
+	}
+	return nil, os.ErrNotExist
+}
+
+// parseManifest 解析 MANIFEST.MF 的主属性，以空格开头的续行拼接到上一行
+func parseManifest(r io.Reader) (map[string]string, error) {
+	attrs := map[string]string{}
+	var last string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break // 主属性结束，之后是各条目的属性
+		}
+		if line[0] == ' ' && last != "" {
+			attrs[last] += line[1:]
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		last = strings.TrimSpace(key)
+		attrs[last] = strings.TrimSpace(value)
+	}
+	return attrs, scanner.Err()
+}
+
+// javaRelease 返回构建工具使用的版本号，Java 8 及更早为 1.x
+func javaRelease(version int) string {
+	if version <= 8 {
+		return fmt.Sprintf("1.%d", version)
+	}
+	return strconv.Itoa(version)
+}
+
+// ideaLanguageLevel 返回 IDEA 的语言级别，如 JDK_1_8、JDK_17
+func ideaLanguageLevel(version int) string {
+	if version <= 9 {
+		return fmt.Sprintf("JDK_1_%d", version)
+	}
+	return fmt.Sprintf("JDK_%d", version)
+}
+
+// gradleJavaVersion 返回 Gradle 的 JavaVersion 常量名，如 VERSION_1_8、VERSION_17
+func gradleJavaVersion(version int) string {
+	if version <= 10 {
+		return fmt.Sprintf("VERSION_1_%d", version)
+	}
+	return fmt.Sprintf("VERSION_%d", version)
+}
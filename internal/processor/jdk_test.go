@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{"Build-Jdk-Spec", "17", 17},
+		{"旧版本号", "1.8", 8},
+		{"Build-Jdk", "1.8.0_292", 8},
+		{"Created-By 带厂商", "1.6.0_45 (Sun Microsystems Inc.)", 6},
+		{"新版本号带厂商", "17.0.2 (Eclipse Adoptium)", 17},
+		{"构建工具", "Apache Maven 3.6.3", 0},
+		{"空值", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseJavaVersion(tt.value); got != tt.want {
+				t.Errorf("parseJavaVersion(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectJavaVersion(t *testing.T) {
+	dir := t.TempDir()
+	jar := filepath.Join(dir, "app.jar")
+	manifest := zipEntry{name: "META-INF/MANIFEST.MF", data: []byte("Manifest-Version: 1.0\r\nCreated-By: Apache Maven 3.6.3\r\nBuild-Jdk: 1.8.0_201\r\n\r\nName: com/acme/\r\nBuild-Jdk-Spec: 21\r\n")}
+	os.WriteFile(jar, buildZip(t, []zipEntry{manifest}), 0644)
+	empty := filepath.Join(dir, "empty.jar")
+	os.WriteFile(empty, buildZip(t, nil), 0644)
+
+	tests := []struct {
+		name          string
+		input         string
+		classVersions map[int]int
+		version       int
+		source        string
+	}{
+		{"class 版本优先", jar, map[int]int{6: 10, 11: 2}, 6, JavaVersionFromClass},
+		{"数量相同取较高版本", jar, map[int]int{8: 5, 11: 5, 17: 1}, 11, JavaVersionFromClass},
+		{"Java 1.4 及更早", jar, map[int]int{1: 3}, 4, JavaVersionFromClass},
+		{"MANIFEST.MF 主属性", jar, nil, 8, "Build-Jdk"},
+		{"默认版本", empty, nil, DefaultJavaVersion, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, source := DetectJavaVersion(tt.input, tt.classVersions)
+			if version != tt.version || source != tt.source {
+				t.Errorf("DetectJavaVersion() = %d, %q, want %d, %q", version, source, tt.version, tt.source)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"archive/zip"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// ratioMinSize 小于该大小的条目不检查压缩比，避免误判高度重复的小文件
+const ratioMinSize = 1 << 20
+
+// ArchiveLimits 处理不可信压缩包时的资源限制，0 表示不限制
+type ArchiveLimits struct {
+	MaxTotalBytes int64   // 单个输入压缩包（含嵌套 JAR）解压和读取的总字节数
+	MaxEntries    int     // 单个压缩包的条目数
+	MaxRatio      float64 // 单个条目的压缩比（解压后大小 / 压缩后大小）
+	MaxDepth      int     // JAR 嵌套层级，顶层压缩包为 0
+}
+
+// DefaultArchiveLimits 返回默认限制，足以处理常见的大型 Spring Boot 应用
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxTotalBytes: 8 << 30,
+		MaxEntries:    500000,
+		MaxRatio:      200,
+		MaxDepth:      3,
+	}
+}
+
+// archiveBudget 单个输入压缩包及其嵌套 JAR 共享的解压额度
+type archiveBudget struct {
+	used     atomic.Int64
+	exceeded atomic.Bool // 已超出总大小限制，后续条目全部跳过
+}
+
+// checkEntries 检查条目数
+func (l ArchiveLimits) checkEntries(r *zip.Reader) error {
+	if l.MaxEntries > 0 && len(r.File) > l.MaxEntries {
+		return fmt.Errorf(i18n.T("条目数 %d 超过限制 %d"), len(r.File), l.MaxEntries)
+	}
+	return nil
+}
+
+// checkDepth 检查嵌套层级
+func (l ArchiveLimits) checkDepth(depth int) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf(i18n.T("嵌套层级 %d 超过限制 %d"), depth, l.MaxDepth)
+	}
+	return nil
+}
+
+// checkRatio 检查单个条目的压缩比
+func (l ArchiveLimits) checkRatio(f *zip.File) error {
+	if l.MaxRatio <= 0 || f.UncompressedSize64 < ratioMinSize || f.CompressedSize64 == 0 {
+		return nil
+	}
+	ratio := float64(f.UncompressedSize64) / float64(f.CompressedSize64)
+	if ratio > l.MaxRatio {
+		return fmt.Errorf(i18n.T("压缩比 %.0f 超过限制 %.0f"), ratio, l.MaxRatio)
+	}
+	return nil
+}
+
+// reserve 在读取条目前预留解压额度，超出总大小限制时返回错误
+// first 表示本次调用是否首次超出限制，只在首次超出时写入报告
+func (b *archiveBudget) reserve(l ArchiveLimits, f *zip.File) (first bool, err error) {
+	if b.exceeded.Load() {
+		return false, fmt.Errorf(i18n.T("解压总大小已超过限制"))
+	}
+	used := b.used.Add(int64(f.UncompressedSize64))
+	if l.MaxTotalBytes > 0 && used > l.MaxTotalBytes {
+		return b.exceeded.CompareAndSwap(false, true), fmt.Errorf(i18n.T("解压总大小超过限制 %d 字节"), l.MaxTotalBytes)
+	}
+	return false, nil
+}
+
+// 报告中资源限制的类型
+const (
+	LimitEntries = "entries"
+	LimitSize    = "size"
+	LimitRatio   = "ratio"
+	LimitDepth   = "depth"
+)
+
+// allowEntry 检查条目的压缩比和解压总大小，超出限制时写入报告并返回 false
+// archive/zip 会在实际数据超过声明大小时返回错误，因此按声明大小预留即可
+func (p *JarProcessor) allowEntry(f *zip.File, archive string, rpt *report.Report) bool {
+	limits := p.filterConfig.Limits
+	if err := limits.checkRatio(f); err != nil {
+		rpt.AddWarning(limitWarning(archive, f.Name, LimitRatio, err))
+		return false
+	}
+	if first, err := p.budget.reserve(limits, f); err != nil {
+		if first {
+			rpt.AddWarning(limitWarning(archive, f.Name, LimitSize, err))
+		}
+		return false
+	}
+	return true
+}
+
+// limitWarning 构造资源限制的报告条目
+func limitWarning(archive, entry, kind string, err error) report.Warning {
+	return report.Warning{
+		Archive: archive,
+		Entry:   entry,
+		Kind:    kind,
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,361 @@
+package processor
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// localGroupID 未找到 Maven 坐标的依赖使用的 groupId
+const localGroupID = "local"
+
+// Dependency 依赖 JAR 对应的 Maven 坐标
+type Dependency struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	File       string // libs 目录中的文件名
+	Resolved   bool   // 是否从 pom.properties 读取到坐标，false 时只能以本地文件引用
+}
+
+// Coordinate 返回 groupId:artifactId:version
+func (d Dependency) Coordinate() string {
+	return d.GroupID + ":" + d.ArtifactID + ":" + d.Version
+}
+
+// jarVersionPattern 从 JAR 文件名中拆分名称和版本，如 foo-bar-1.2.3.jar
+var jarVersionPattern = regexp.MustCompile(`^(.+?)-(\d[\w.\-]*)$`)
+
+// ResolveDependencies 读取 libsDir 中每个 JAR 内的 META-INF/maven/*/*/pom.properties，
+// 得到依赖的 Maven 坐标；没有或无法确定坐标的 JAR 返回 Resolved 为 false 的依赖，
+// 其 artifactId 和版本取自文件名。结果按文件名排序，相同 groupId:artifactId 只保留第一个
+func ResolveDependencies(libsDir string) ([]Dependency, error) {
+	files, err := filepath.Glob(filepath.Join(libsDir, "*.jar"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var deps []Dependency
+	seen := map[string]bool{}
+	for _, file := range files {
+		dep, ok := readJarCoordinates(file)
+		if ok {
+			key := dep.GroupID + ":" + dep.ArtifactID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		} else {
+			dep = localDependency(filepath.Base(file))
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// readJarCoordinates 读取 JAR 中的 pom.properties
+// 含多份 pom.properties 的 JAR（如 shade 打包）取与文件名匹配的一份，无法确定时返回 false
+func readJarCoordinates(file string) (Dependency, bool) {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return Dependency{}, false
+	}
+	defer r.Close()
+
+	name := strings.TrimSuffix(filepath.Base(file), path.Ext(file))
+	var candidates []Dependency
+	for _, f := range r.File {
+		if !isPomProperties(f.Name) {
+			continue
+		}
+		props, err := readZipProperties(f)
+		if err != nil {
+			continue
+		}
+		dep := Dependency{
+			GroupID:    props["groupId"],
+			ArtifactID: props["artifactId"],
+			Version:    props["version"],
+			File:       filepath.Base(file),
+			Resolved:   true,
+		}
+		if dep.GroupID == "" || dep.ArtifactID == "" || dep.Version == "" {
+			continue
+		}
+		candidates = append(candidates, dep)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return Dependency{}, false
+	case 1:
+		return candidates[0], true
+	}
+	for _, dep := range candidates {
+		if name == dep.ArtifactID+"-"+dep.Version || strings.HasPrefix(name, dep.ArtifactID+"-"+dep.Version+"-") {
+			return dep, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// localDependency 以文件名构造本地依赖，如 foo-1.2.jar 为 local:foo:1.2
+func localDependency(file string) Dependency {
+	name := strings.TrimSuffix(file, filepath.Ext(file))
+	dep := Dependency{GroupID: localGroupID, ArtifactID: name, Version: "1.0", File: file}
+	if m := jarVersionPattern.FindStringSubmatch(name); m != nil {
+		dep.ArtifactID, dep.Version = m[1], m[2]
+	}
+	return dep
+}
+
+// isPomProperties 判断条目是否为 META-INF/maven/<groupId>/<artifactId>/pom.properties
+func isPomProperties(name string) bool {
+	parts := strings.Split(name, "/")
+	return len(parts) == 5 && parts[0] == "META-INF" && parts[1] == "maven" && parts[4] == "pom.properties"
+}
+
+// readZipProperties 读取压缩包中的 properties 文件
+func readZipProperties(f *zip.File) (map[string]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return parseProperties(rc)
+}
+
+// parseProperties 解析 pom.properties 这类简单的 key=value 文件，忽略注释和空行
+func parseProperties(r io.Reader) (map[string]string, error) {
+	props := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, _ = strings.Cut(line, ":")
+		}
+		props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return props, scanner.Err()
+}
+
+// pomModel pom.xml 中用于确定项目坐标的部分
+type pomModel struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+}
+
+// readProjectCoordinates 读取输入自身的 Maven 坐标
+// JAR、WAR 读取根目录下的 META-INF/maven，目录输入读取其中的 META-INF/maven；
+// 优先使用 pom.properties，没有时解析 pom.xml（groupId、version 可继承自 parent）
+func readProjectCoordinates(inputPath string) (Dependency, bool) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return Dependency{}, false
+	}
+
+	files := map[string]func() (io.ReadCloser, error){}
+	if info.IsDir() {
+		matches, _ := filepath.Glob(filepath.Join(inputPath, "META-INF", "maven", "*", "*", "pom.*"))
+		for _, match := range matches {
+			rel, _ := filepath.Rel(inputPath, match)
+			match := match
+			files[filepath.ToSlash(rel)] = func() (io.ReadCloser, error) { return os.Open(match) }
+		}
+	} else {
+		r, err := zip.OpenReader(inputPath)
+		if err != nil {
+			return Dependency{}, false
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			files[f.Name] = f.Open
+		}
+	}
+
+	var names []string
+	for name := range files {
+		if isPomProperties(name) || isPomXML(name) {
+			names = append(names, name)
+		}
+	}
+	// pom.properties 排在 pom.xml 之前
+	sort.Slice(names, func(i, j int) bool {
+		if isPomProperties(names[i]) != isPomProperties(names[j]) {
+			return isPomProperties(names[i])
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		rc, err := files[name]()
+		if err != nil {
+			continue
+		}
+		dep, err := readCoordinates(name, rc)
+		rc.Close()
+		if err == nil && dep.GroupID != "" && dep.ArtifactID != "" && dep.Version != "" {
+			dep.Resolved = true
+			return dep, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// isPomXML 判断条目是否为 META-INF/maven/<groupId>/<artifactId>/pom.xml
+func isPomXML(name string) bool {
+	return isPomProperties(strings.TrimSuffix(name, ".xml") + ".properties")
+}
+
+// readCoordinates 从 pom.properties 或 pom.xml 中读取坐标
+func readCoordinates(name string, r io.Reader) (Dependency, error) {
+	if isPomProperties(name) {
+		props, err := parseProperties(r)
+		if err != nil {
+			return Dependency{}, err
+		}
+		return Dependency{GroupID: props["groupId"], ArtifactID: props["artifactId"], Version: props["version"]}, nil
+	}
+
+	var pom pomModel
+	if err := xml.NewDecoder(r).Decode(&pom); err != nil {
+		return Dependency{}, err
+	}
+	dep := Dependency{GroupID: pom.GroupID, ArtifactID: pom.ArtifactID, Version: pom.Version}
+	if dep.GroupID == "" {
+		dep.GroupID = pom.Parent.GroupID
+	}
+	if dep.Version == "" {
+		dep.Version = pom.Parent.Version
+	}
+	return dep, nil
+}
+
+// projectCoordinates 返回生成的项目使用的坐标，输入中没有 Maven 坐标时使用项目名称
+func projectCoordinates(config *ProjectConfig) Dependency {
+	if project, ok := readProjectCoordinates(config.InputPath); ok {
+		return project
+	}
+	return Dependency{GroupID: "decompiled", ArtifactID: config.ProjectName, Version: "1.0.0-SNAPSHOT"}
+}
+
+// isWar 判断输入是否为 WAR 文件
+func isWar(inputPath string) bool {
+	return strings.EqualFold(filepath.Ext(inputPath), ".war")
+}
+
+// GenerateMavenProject 在输出目录生成 pom.xml
+// 项目坐标取自输入中的 pom.properties 或 pom.xml，依赖取自 libs 目录中各 JAR 的
+// pom.properties；无法确定坐标的 JAR 以 system 范围引用 libs 中的文件
+func GenerateMavenProject(config *ProjectConfig) ([]Dependency, error) {
+	deps, err := ResolveDependencies(config.LibsDir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("读取依赖 JAR 失败: %v"), err)
+	}
+
+	project := projectCoordinates(config)
+	packaging := "jar"
+	if isWar(config.InputPath) {
+		packaging = "war"
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 https://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+
+`)
+	fmt.Fprintf(&b, "  <groupId>%s</groupId>\n", xmlText(project.GroupID))
+	fmt.Fprintf(&b, "  <artifactId>%s</artifactId>\n", xmlText(project.ArtifactID))
+	fmt.Fprintf(&b, "  <version>%s</version>\n", xmlText(project.Version))
+	fmt.Fprintf(&b, "  <packaging>%s</packaging>\n", packaging)
+	release := javaRelease(config.javaVersion())
+	fmt.Fprintf(&b, `
+  <properties>
+    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+    <maven.compiler.source>%s</maven.compiler.source>
+    <maven.compiler.target>%s</maven.compiler.target>
+  </properties>
+`, release, release)
+
+	if len(deps) > 0 {
+		libsDir := projectPath(config, config.LibsDir)
+		b.WriteString("\n  <dependencies>\n")
+		for _, dep := range deps {
+			b.WriteString("    <dependency>\n")
+			fmt.Fprintf(&b, "      <groupId>%s</groupId>\n", xmlText(dep.GroupID))
+			fmt.Fprintf(&b, "      <artifactId>%s</artifactId>\n", xmlText(dep.ArtifactID))
+			fmt.Fprintf(&b, "      <version>%s</version>\n", xmlText(dep.Version))
+			if !dep.Resolved {
+				b.WriteString("      <scope>system</scope>\n")
+				fmt.Fprintf(&b, "      <systemPath>${project.basedir}/%s/%s</systemPath>\n", xmlText(libsDir), xmlText(dep.File))
+			}
+			b.WriteString("    </dependency>\n")
+		}
+		b.WriteString("  </dependencies>\n")
+	}
+
+	b.WriteString("\n  <build>\n")
+	fmt.Fprintf(&b, "    <sourceDirectory>%s</sourceDirectory>\n", xmlText(projectPath(config, config.SrcDir)))
+	b.WriteString("    <resources>\n      <resource>\n")
+	fmt.Fprintf(&b, "        <directory>%s</directory>\n", xmlText(projectPath(config, config.ResourcesDir)))
+	b.WriteString("      </resource>\n    </resources>\n")
+	if packaging == "war" {
+		b.WriteString(`    <plugins>
+      <plugin>
+        <groupId>org.apache.maven.plugins</groupId>
+        <artifactId>maven-war-plugin</artifactId>
+        <version>3.4.0</version>
+        <configuration>
+          <failOnMissingWebXml>false</failOnMissingWebXml>
+        </configuration>
+      </plugin>
+    </plugins>
+`)
+	}
+	b.WriteString("  </build>\n</project>\n")
+
+	pomPath := filepath.Join(config.OutputDir, "pom.xml")
+	if err := os.WriteFile(pomPath, []byte(b.String()), 0644); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// projectPath 返回相对于输出根目录的路径，使用 / 分隔
+func projectPath(config *ProjectConfig, dir string) string {
+	rel, err := filepath.Rel(config.OutputDir, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// xmlText 转义 XML 文本
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
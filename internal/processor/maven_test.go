@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pomProperties 生成 pom.properties 条目
+func pomProperties(group, artifact, version string) zipEntry {
+	return zipEntry{
+		name: "META-INF/maven/" + group + "/" + artifact + "/pom.properties",
+		data: []byte("#Generated by Maven\ngroupId=" + group + "\nartifactId=" + artifact + "\nversion=" + version + "\n"),
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	libsDir := t.TempDir()
+	jars := map[string][]zipEntry{
+		"spring-core-5.3.31.jar": {pomProperties("org.springframework", "spring-core", "5.3.31")},
+		"shaded-1.0.jar":         {pomProperties("com.acme", "shaded", "1.0"), pomProperties("com.google.guava", "guava", "31.1")},
+		"ambiguous.jar":          {pomProperties("a", "x", "1"), pomProperties("b", "y", "2")},
+		"legacy-util-2.4.jar":    {{name: "com/legacy/Util.class", data: []byte("x")}},
+		"spring-core-copy.jar":   {pomProperties("org.springframework", "spring-core", "5.3.31")},
+	}
+	for name, entries := range jars {
+		if err := os.WriteFile(filepath.Join(libsDir, name), buildZip(t, entries), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deps, err := ResolveDependencies(libsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Dependency{}
+	for _, dep := range deps {
+		got[dep.File] = dep
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		coordinate string
+		resolved   bool
+	}{
+		{"单个 pom.properties", "spring-core-5.3.31.jar", "org.springframework:spring-core:5.3.31", true},
+		{"多个 pom.properties 按文件名选择", "shaded-1.0.jar", "com.acme:shaded:1.0", true},
+		{"无法确定时使用文件名", "ambiguous.jar", "local:ambiguous:1.0", false},
+		{"没有 pom.properties", "legacy-util-2.4.jar", "local:legacy-util:2.4", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, ok := got[tt.file]
+			if !ok {
+				t.Fatalf("%s not resolved", tt.file)
+			}
+			if dep.Coordinate() != tt.coordinate || dep.Resolved != tt.resolved {
+				t.Errorf("%s = %s (resolved %v), want %s (resolved %v)", tt.file, dep.Coordinate(), dep.Resolved, tt.coordinate, tt.resolved)
+			}
+		})
+	}
+	if _, ok := got["spring-core-copy.jar"]; ok {
+		t.Error("duplicate coordinate not skipped")
+	}
+}
+
+func TestGenerateMavenProject(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "app.war")
+	appPom := zipEntry{name: "META-INF/maven/com.acme/shop/pom.xml", data: []byte(`<project>
+  <parent><groupId>com.acme</groupId><version>2.1.0</version></parent>
+  <artifactId>shop</artifactId>
+</project>`)}
+	if err := os.WriteFile(input, buildZip(t, []zipEntry{appPom}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "out")
+	config := &ProjectConfig{
+		ProjectName:  "out",
+		InputPath:    input,
+		OutputDir:    output,
+		SrcDir:       filepath.Join(output, "src"),
+		ResourcesDir: filepath.Join(output, "src", "resources"),
+		LibsDir:      filepath.Join(output, "libs"),
+		JavaVersion:  17,
+	}
+	os.MkdirAll(config.LibsDir, 0755)
+	os.WriteFile(filepath.Join(config.LibsDir, "gson-2.10.jar"), buildZip(t, []zipEntry{pomProperties("com.google.code.gson", "gson", "2.10")}), 0644)
+	os.WriteFile(filepath.Join(config.LibsDir, "vendor.jar"), buildZip(t, nil), 0644)
+
+	if _, err := GenerateMavenProject(config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(output, "pom.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pom := string(data)
+	for _, want := range []string{
+		"<groupId>com.acme</groupId>\n  <artifactId>shop</artifactId>\n  <version>2.1.0</version>\n  <packaging>war</packaging>",
+		"<groupId>com.google.code.gson</groupId>\n      <artifactId>gson</artifactId>\n      <version>2.10</version>\n    </dependency>",
+		"<systemPath>${project.basedir}/libs/vendor.jar</systemPath>",
+		"<sourceDirectory>src</sourceDirectory>",
+		"<directory>src/resources</directory>",
+		"<maven.compiler.source>17</maven.compiler.source>",
+	} {
+		if !strings.Contains(pom, want) {
+			t.Errorf("pom.xml missing %q:\n%s", want, pom)
+		}
+	}
+}
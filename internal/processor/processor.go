@@ -2,16 +2,20 @@ package processor
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/fatih/color"
-	"github.com/jiaozhu/emorad/internal/cfr"
+	"github.com/jiaozhu/emorad/internal/cache"
+	"github.com/jiaozhu/emorad/internal/classfile"
+	"github.com/jiaozhu/emorad/internal/engine"
 	"github.com/jiaozhu/emorad/internal/report"
 )
 
@@ -37,15 +41,47 @@ var DefaultExcludes = []string{
 	"org/bouncycastle/",
 }
 
-// FilterConfig 过滤配置
+// FilterConfig 过滤配置及其他运行设置，处理过程中不会修改
+// 输出目录、缓存等运行时才确定的内容见 RunContext
 type FilterConfig struct {
-	Includes      []string // 包含的包前缀（优先级最高）
-	Excludes      []string // 排除的包前缀
-	SkipLibs      bool     // 是否跳过 lib 目录下的 JAR
-	JarIncludes   []string // JAR 名称必须包含的关键字
-	CopyResources bool     // 是否复制配置文件到输出目录
-	CopyLibJars   bool     // 是否复制依赖 JAR 到 libs 目录
-	GenerateIDEA  bool     // 是否生成 IDEA 项目配置
+	Includes        []string          // 包含规则，非空时只处理匹配的 class
+	Excludes        []string          // 排除规则，在包含规则匹配的范围内生效
+	SkipLibs        bool              // 是否跳过 lib 目录下的 JAR
+	JarIncludes     []string          // JAR 名称必须包含的关键字
+	CopyResources   bool              // 是否复制配置文件到输出目录
+	CopyLibJars     bool              // 是否复制依赖 JAR 到 libs 目录
+	GenerateIDEA    bool              // 是否生成 IDEA 项目配置
+	GenerateMaven   bool              // 是否生成 Maven pom.xml
+	GenerateGradle  string            // 生成 Gradle 构建文件使用的 DSL：groovy、kotlin，为空时不生成
+	GenerateEclipse bool              // 是否生成 Eclipse 项目配置
+	GenerateVSCode  bool              // 是否生成 VS Code 项目配置
+	Layout          string            // 输出目录结构：flat、maven，为空时为 flat
+	BatchSize       int               // 每个 CFR 进程处理的 class 数量，0 表示逐个处理
+	UseDaemon       bool              // 是否使用常驻 CFR 进程
+	Engine          string            // 反编译引擎名称，为空时使用 CFR
+	FallbackEngine  string            // 主引擎失败时使用的备用引擎，为空时不重试
+	ClassTimeout    time.Duration     // 单个 class 的反编译超时，0 表示不限制
+	CFRJar          string            // 指定 CFR JAR 路径，为空时自动查找或下载
+	CFROptions      map[string]string // 传给 CFR 的选项，来自配置文件和 --cfr-opt
+	CacheDir        string            // 反编译结果缓存目录，为空时不使用缓存
+	Mirrors         []string          // 反编译器下载镜像（Maven 仓库根地址或含 {file} 的模板）
+	Limits          ArchiveLimits     // 处理压缩包时的资源限制
+	Resume          bool              // 是否跳过上次中断的运行中已完成的内容
+	MaxErrorRate    float64           // 允许的最大失败率（0-1），超过时以部分失败退出
+
+	compileOnce sync.Once      // 首次使用时编译过滤规则
+	includes    []classPattern // 编译后的包含规则
+	excludes    []classPattern // 编译后的排除规则
+	filterErr   error          // 过滤规则的编译错误
+}
+
+// RunContext 一次运行中由 decompile.Run 根据 FilterConfig 和目录结构确定的输出位置和缓存
+// 目录为空表示不复制对应的内容
+type RunContext struct {
+	ResourcesDir string       // 配置文件的复制目录
+	LibsDir      string       // 依赖 JAR 的复制目录
+	WebappDir    string       // WAR 中 web 内容的复制目录
+	Cache        *cache.Cache // 反编译结果缓存，nil 表示不使用
 }
 
 // NewDefaultFilterConfig 创建默认过滤配置
@@ -54,30 +90,34 @@ func NewDefaultFilterConfig() *FilterConfig {
 		Includes: nil,
 		Excludes: DefaultExcludes,
 		SkipLibs: true,
+		Limits:   DefaultArchiveLimits(),
+	}
+}
+
+// Validate 编译包含和排除规则，规则无效时返回错误
+func (f *FilterConfig) Validate() error {
+	f.compileOnce.Do(f.compileFilters)
+	return f.filterErr
+}
+
+func (f *FilterConfig) compileFilters() {
+	if f.includes, f.filterErr = compileClassPatterns(f.Includes); f.filterErr != nil {
+		return
 	}
+	f.excludes, f.filterErr = compileClassPatterns(f.Excludes)
 }
 
 // ShouldProcessClass 判断是否应该处理该 class 文件
 // baseDir 是解压后的临时目录
+// 先应用包含规则（未设置时包含全部），再在其范围内应用排除规则
 func (f *FilterConfig) ShouldProcessClass(classPath, baseDir string) bool {
 	relativePath := extractRelativePathFromBase(classPath, baseDir)
+	f.compileOnce.Do(f.compileFilters)
 
-	if len(f.Includes) > 0 {
-		for _, include := range f.Includes {
-			if strings.HasPrefix(relativePath, include) {
-				return true
-			}
-		}
+	if len(f.Includes) > 0 && !matchClassPatterns(f.includes, relativePath) {
 		return false
 	}
-
-	for _, exclude := range f.Excludes {
-		if strings.HasPrefix(relativePath, exclude) {
-			return false
-		}
-	}
-
-	return true
+	return !matchClassPatterns(f.excludes, relativePath)
 }
 
 // ShouldProcessJar 判断是否应该处理该 JAR 文件
@@ -133,41 +173,89 @@ func extractRelativePathFromBase(classPath, baseDir string) string {
 }
 
 // Processor 定义文件处理器接口
+// ctx 取消后处理器应尽快返回，已完成的结果保留在报告中
 type Processor interface {
-	Process(inputPath string, outputDir string, rpt *report.Report) error
+	Process(ctx context.Context, inputPath string, outputDir string, rpt *report.Report) error
 	GetType() string
 }
 
 // ClassProcessor 处理单个.class文件
 type ClassProcessor struct {
-	cfrManager *cfr.Manager
+	decompiler engine.Decompiler
+	timeout    time.Duration // 单个 class 的超时时间，0 表示不限制
+	cache      *cache.Cache  // 反编译结果缓存，nil 表示不使用
+}
+
+func NewClassProcessor(decompiler engine.Decompiler, timeout time.Duration) *ClassProcessor {
+	return &ClassProcessor{decompiler: decompiler, timeout: timeout}
 }
 
-func NewClassProcessor(cfrManager *cfr.Manager) *ClassProcessor {
-	return &ClassProcessor{cfrManager: cfrManager}
+// WithCache 使用缓存跳过内容未变化的 class
+func (p *ClassProcessor) WithCache(c *cache.Cache) *ClassProcessor {
+	p.cache = c
+	return p
 }
 
 func (p *ClassProcessor) GetType() string {
 	return "class"
 }
 
-func (p *ClassProcessor) Process(inputPath string, outputDir string, rpt *report.Report) error {
+func (p *ClassProcessor) Process(ctx context.Context, inputPath string, outputDir string, rpt *report.Report) error {
+	return p.ProcessGroup(ctx, ClassGroup{Path: inputPath}, outputDir, rpt)
+}
+
+// ProcessGroup 反编译一个源文件对应的 class 组，在报告中记为一条结果
+func (p *ClassProcessor) ProcessGroup(ctx context.Context, group ClassGroup, outputDir string, rpt *report.Report) error {
+	out := rpt.Console()
+	inputPath := group.Path
 	startTime := time.Now()
+	packageName, javaVersion := classInfo(inputPath)
 	result := report.Result{
-		ClassName:   filepath.Base(inputPath),
-		PackageName: ExtractPackageName(inputPath),
-		Success:     false,
-		TimeStamp:   startTime,
+		ClassName:    filepath.Base(inputPath),
+		PackageName:  packageName,
+		JavaVersion:  javaVersion,
+		Path:         group.Location,
+		Success:      false,
+		InnerClasses: len(group.Inners),
+		TimeStamp:    startTime,
 	}
 
-	err := p.cfrManager.Decompile(inputPath, outputDir)
-	if err != nil {
+	key, entry := lookupCache(p.cache, group, outputDir)
+	if entry != nil {
+		result.Success = true
+		result.Cached = true
+		result.Engine = entry.Engine
+		result.TimeTaken = time.Since(startTime).Seconds()
+		out.Green(out.T("✓ %s (缓存)"), result.ClassName)
+		rpt.AddResult(result)
+		return nil
+	}
+
+	classCtx := ctx
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		classCtx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	engineName, err := engine.DecompileWithEngine(classCtx, p.decompiler, inputPath, outputDir)
+	if err != nil && ctx.Err() != nil {
+		// 整体运行被中断，该 class 不计入报告
+		return ctx.Err()
+	}
+
+	result.Engine = engineName
+	if errors.Is(err, context.DeadlineExceeded) {
+		result.Error = fmt.Sprintf(out.T("反编译超时: 超过 %s"), p.timeout)
+		out.Red(out.T("✗ %s (超时)"), result.ClassName)
+	} else if err != nil {
 		result.Success = false
-		result.Error = fmt.Sprintf("反编译失败: %v", err)
-		color.Red("✗ %s", result.ClassName)
+		result.Error = fmt.Sprintf(out.T("反编译失败: %v"), err)
+		out.Red("✗ %s", result.ClassName)
 	} else {
 		result.Success = true
-		color.Green("✓ %s", result.ClassName)
+		out.Green("✓ %s", result.ClassName)
+		storeCache(p.cache, key, group, outputDir, engineName)
 	}
 
 	result.TimeTaken = time.Since(startTime).Seconds()
@@ -177,16 +265,21 @@ func (p *ClassProcessor) Process(inputPath string, outputDir string, rpt *report
 
 // JarProcessor 处理JAR文件
 type JarProcessor struct {
-	cfrManager   *cfr.Manager
+	decompiler   engine.Decompiler
 	workers      int
 	filterConfig *FilterConfig
+	run          RunContext
+
+	depth  int            // 嵌套层级，顶层压缩包为 0
+	budget *archiveBudget // 与嵌套 JAR 共享的解压额度
 }
 
-func NewJarProcessor(cfrManager *cfr.Manager, workers int, filterConfig *FilterConfig) *JarProcessor {
+func NewJarProcessor(decompiler engine.Decompiler, workers int, filterConfig *FilterConfig, run RunContext) *JarProcessor {
 	return &JarProcessor{
-		cfrManager:   cfrManager,
+		decompiler:   decompiler,
 		workers:      workers,
 		filterConfig: filterConfig,
+		run:          run,
 	}
 }
 
@@ -194,99 +287,249 @@ func (p *JarProcessor) GetType() string {
 	return "jar"
 }
 
-func (p *JarProcessor) Process(inputPath string, outputDir string, rpt *report.Report) error {
-	color.Cyan("正在处理JAR文件: %s", filepath.Base(inputPath))
-
-	tempDir := filepath.Join(os.TempDir(), fmt.Sprintf("emorad-%s-%d",
-		filepath.Base(inputPath), time.Now().Unix()))
-	defer os.RemoveAll(tempDir)
+func (p *JarProcessor) Process(ctx context.Context, inputPath string, outputDir string, rpt *report.Report) error {
+	return p.processFile(ctx, inputPath, filepath.Base(inputPath), outputDir, rpt)
+}
 
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("创建临时目录失败: %v", err)
+// processFile 与 Process 相同，label 为报告中显示的压缩包路径
+// 目录中可能存在同名压缩包，因此使用相对于输入目录的路径区分
+func (p *JarProcessor) processFile(ctx context.Context, inputPath, label string, outputDir string, rpt *report.Report) error {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf(rpt.Console().T("打开JAR文件失败: %v"), err)
 	}
+	defer f.Close()
 
-	if err := UnzipFile(inputPath, tempDir); err != nil {
-		return fmt.Errorf("解压JAR文件失败: %v", err)
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf(rpt.Console().T("打开JAR文件失败: %v"), err)
 	}
 
-	classFiles, nestedJars, resourceFiles, err := ScanDirectory(tempDir)
+	p.budget = &archiveBudget{}
+	return p.processArchive(ctx, f, info.Size(), label, outputDir, rpt)
+}
+
+// processArchive 处理以 io.ReaderAt 打开的压缩包
+// 先按条目过滤，只解压需要反编译的 class，配置文件和依赖 JAR 直接写入输出目录，
+// 嵌套 JAR 在内存中打开后递归处理。label 为报告中显示的压缩包路径，如 app.jar!/BOOT-INF/lib/a.jar
+// 超出资源限制的压缩包或条目会被跳过并记录到报告中
+func (p *JarProcessor) processArchive(ctx context.Context, ra io.ReaderAt, size int64, label string, outputDir string, rpt *report.Report) error {
+	out := rpt.Console()
+	name := path.Base(label)
+	out.Cyan(out.T("正在处理JAR文件: %s"), name)
+	rpt.StartArchive(label)
+
+	r, err := zip.NewReader(ra, size)
 	if err != nil {
-		return fmt.Errorf("扫描目录失败: %v", err)
+		return fmt.Errorf(out.T("读取JAR文件失败: %v"), err)
+	}
+	if err := p.filterConfig.Limits.checkEntries(r); err != nil {
+		rpt.AddWarning(limitWarning(label, "", LimitEntries, err))
+		return nil
 	}
+	entries := scanArchive(r)
 
-	if p.filterConfig.CopyResources && len(resourceFiles) > 0 {
+	if p.run.ResourcesDir != "" && len(entries.resources) > 0 {
 		copiedCount := 0
-		for _, resFile := range resourceFiles {
-			if err := CopyResourceFile(resFile, tempDir, outputDir); err != nil {
-				color.Red("复制配置文件失败: %s - %v", filepath.Base(resFile), err)
+		for _, res := range entries.resources {
+			if !p.allowEntry(res, label, rpt) {
+				continue
+			}
+			if err := copyResourceEntry(res, p.run.ResourcesDir); err != nil {
+				out.Red(out.T("复制配置文件失败: %s - %v"), path.Base(res.Name), err)
 			} else {
 				copiedCount++
 			}
 		}
 		if copiedCount > 0 {
-			color.Green("[OK] 复制了 %d 个配置文件", copiedCount)
+			out.Green(out.T("[OK] 复制了 %d 个配置文件"), copiedCount)
 		}
 	}
 
-	filteredClasses := make([]string, 0, len(classFiles))
-	for _, classPath := range classFiles {
-		if p.filterConfig.ShouldProcessClass(classPath, tempDir) {
-			filteredClasses = append(filteredClasses, classPath)
+	// WAR 中 WEB-INF/classes、WEB-INF/lib 以外的内容（web.xml、JSP、静态资源）写入 webapp 目录
+	// webapp 目录只在 maven 结构下设置，web 内容是项目的一部分，不需要 -r
+	if p.run.WebappDir != "" && p.depth == 0 &&
+		strings.EqualFold(path.Ext(label), ".war") && len(entries.webapp) > 0 {
+		copiedCount := 0
+		for _, f := range entries.webapp {
+			if !p.allowEntry(f, label, rpt) {
+				continue
+			}
+			destPath, err := entryPath(p.run.WebappDir, f.Name)
+			if err == nil {
+				err = extractEntry(f, destPath)
+			}
+			if err != nil {
+				out.Red(out.T("复制 web 内容失败: %s - %v"), f.Name, err)
+			} else {
+				copiedCount++
+			}
+		}
+		if copiedCount > 0 {
+			out.Green(out.T("[OK] 复制了 %d 个 web 内容文件到 webapp 目录"), copiedCount)
 		}
 	}
 
-	if len(classFiles) != len(filteredClasses) {
-		color.Yellow("[FILTER] 过滤后: %d/%d 个 class 文件需要处理", len(filteredClasses), len(classFiles))
+	tempDir, err := os.MkdirTemp("", "emorad-"+name+"-")
+	if err != nil {
+		return fmt.Errorf(out.T("创建临时目录失败: %v"), err)
 	}
+	defer os.RemoveAll(tempDir)
 
-	rpt.AddExpectedFiles(int32(len(filteredClasses)))
+	// 按源文件分组，由顶层类决定整组是否处理，内部类随之解压供反编译器读取
+	classEntries := make(map[string]*zip.File, len(entries.classes))
+	classNames := make([]string, len(entries.classes))
+	for i, entry := range entries.classes {
+		classEntries[entry.Name] = entry
+		classNames[i] = entry.Name
+	}
+
+	var groups []ClassGroup
+	filteredCount, resumed := 0, 0
+	for _, nameGroup := range GroupClasses(classNames) {
+		outerPath, err := entryPath(tempDir, nameGroup.Path)
+		if err != nil {
+			return fmt.Errorf(out.T("解压JAR文件失败: %v"), err)
+		}
+		// 过滤规则按 class 文件中的类名匹配，条目不在与包名一致的目录下时同样生效
+		filterPath := outerPath
+		if classPath := archiveClassPath(classEntries[nameGroup.Path], p.filterConfig.Limits); classPath != "" {
+			filterPath = filepath.Join(tempDir, filepath.FromSlash(classPath))
+		}
+		if !p.filterConfig.ShouldProcessClass(filterPath, tempDir) {
+			continue
+		}
+		location := label + "!/" + nameGroup.Path
+		if rpt.Completed(location) {
+			resumed++
+			continue
+		}
+
+		group := ClassGroup{Location: location}
+		for _, name := range append([]string{nameGroup.Path}, nameGroup.Inners...) {
+			entry := classEntries[name]
+			classPath, err := entryPath(tempDir, name)
+			if err != nil {
+				return fmt.Errorf(out.T("解压JAR文件失败: %v"), err)
+			}
+			if !p.allowEntry(entry, label, rpt) {
+				continue
+			}
+			if err := extractEntry(entry, classPath); err != nil {
+				return fmt.Errorf(out.T("解压JAR文件失败: %v"), err)
+			}
+			if group.Path == "" {
+				group.Path = classPath
+			} else {
+				group.Inners = append(group.Inners, classPath)
+			}
+		}
+		if group.Path != "" {
+			groups = append(groups, group)
+			filteredCount += 1 + len(group.Inners)
+		}
+	}
+
+	if len(entries.classes) != filteredCount {
+		out.Yellow(out.T("[FILTER] 过滤后: %d/%d 个 class 文件需要处理"), filteredCount, len(entries.classes))
+	}
+
+	if resumed > 0 {
+		out.Cyan(out.T("[RESUME] 跳过上次已完成的 %d 个源文件"), resumed)
+	}
+
+	rpt.AddExpectedFiles(int32(len(groups)))
 
 	// 复制依赖 JAR 到 libs 目录
-	if p.filterConfig.CopyLibJars && len(nestedJars) > 0 {
-		copiedJars, err := CopyLibJars(nestedJars, outputDir)
+	if p.run.LibsDir != "" && len(entries.nestedJars) > 0 {
+		libJars := make([]*zip.File, 0, len(entries.nestedJars))
+		for _, jar := range entries.nestedJars {
+			if p.allowEntry(jar, label, rpt) {
+				libJars = append(libJars, jar)
+			}
+		}
+		copiedJars, err := CopyLibJars(libJars, p.run.LibsDir)
+		if copiedJars > 0 {
+			out.Green(out.T("[OK] 复制了 %d 个依赖 JAR 到 libs 目录"), copiedJars)
+		}
 		if err != nil {
-			color.Yellow("[WARN] 复制依赖 JAR 失败: %v", err)
-		} else if copiedJars > 0 {
-			color.Green("[OK] 复制了 %d 个依赖 JAR 到 libs 目录", copiedJars)
+			out.Warn(out.T("[WARN] 复制依赖 JAR 失败: %v"), err)
 		}
 	}
 
-	for _, nestedJar := range nestedJars {
-		if !p.filterConfig.ShouldProcessJar(nestedJar) {
+	for _, nestedJar := range entries.nestedJars {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !p.filterConfig.ShouldProcessJar(nestedJar.Name) {
+			continue
+		}
+		if err := p.filterConfig.Limits.checkDepth(p.depth + 1); err != nil {
+			rpt.AddWarning(limitWarning(label, nestedJar.Name, LimitDepth, err))
+			continue
+		}
+		// 未压缩存储的 JAR 直接引用外层文件，不占用解压额度
+		if nestedJar.Method != zip.Store && !p.allowEntry(nestedJar, label, rpt) {
+			continue
+		}
+		out.Yellow(out.T("处理嵌套JAR: %s"), path.Base(nestedJar.Name))
+		nestedRA, nestedSize, err := openNestedJar(ra, nestedJar)
+		if err != nil {
+			out.Red(out.T("处理嵌套JAR失败: %v"), err)
 			continue
 		}
-		color.Yellow("处理嵌套JAR: %s", filepath.Base(nestedJar))
-		nestedProcessor := NewJarProcessor(p.cfrManager, p.workers, p.filterConfig)
-		if err := nestedProcessor.Process(nestedJar, outputDir, rpt); err != nil {
-			color.Red("处理嵌套JAR失败: %v", err)
+		nestedProcessor := &JarProcessor{
+			decompiler:   p.decompiler,
+			workers:      p.workers,
+			filterConfig: p.filterConfig,
+			run:          p.run,
+			depth:        p.depth + 1,
+			budget:       p.budget,
+		}
+		if err := nestedProcessor.processArchive(ctx, nestedRA, nestedSize, label+"!/"+nestedJar.Name, outputDir, rpt); err != nil {
+			out.Red(out.T("处理嵌套JAR失败: %v"), err)
 		}
 	}
 
-	return p.processClassFiles(filteredClasses, outputDir, rpt)
+	processClassGroups(ctx, p.decompiler, groups, tempDir, outputDir, p.workers, p.filterConfig, p.run.Cache, rpt)
+	return ctx.Err()
 }
 
-func (p *JarProcessor) processClassFiles(classFiles []string, outputDir string, rpt *report.Report) error {
-	jobs := make(chan string, len(classFiles))
+// processClassGroups 并发反编译 class 组，设置了批次大小时按批次处理
+// baseDir 用于推算每个 class 对应的包路径
+func processClassGroups(ctx context.Context, decompiler engine.Decompiler, groups []ClassGroup, baseDir, outputDir string, workers int, filterConfig *FilterConfig, c *cache.Cache, rpt *report.Report) {
+	if len(groups) == 0 {
+		return
+	}
+	if filterConfig.BatchSize > 0 {
+		processClassBatches(ctx, decompiler, groups, baseDir, outputDir, workers, filterConfig, c, rpt)
+		return
+	}
+
+	jobs := make(chan ClassGroup, len(groups))
 	var wg sync.WaitGroup
 
-	for i := 0; i < p.workers; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			processor := NewClassProcessor(p.cfrManager)
-			for classPath := range jobs {
-				processor.Process(classPath, outputDir, rpt)
+			processor := NewClassProcessor(decompiler, filterConfig.ClassTimeout).WithCache(c)
+			for group := range jobs {
+				// 已取消时只消费剩余任务，不再启动新的反编译
+				if ctx.Err() != nil {
+					continue
+				}
+				processor.ProcessGroup(ctx, group, outputDir, rpt)
 			}
 		}()
 	}
 
-	for _, file := range classFiles {
-		jobs <- file
+	for _, group := range groups {
+		jobs <- group
 	}
 	close(jobs)
 
 	wg.Wait()
-	return nil
 }
 
 // WarProcessor 处理WAR文件
@@ -294,9 +537,9 @@ type WarProcessor struct {
 	*JarProcessor
 }
 
-func NewWarProcessor(cfrManager *cfr.Manager, workers int, filterConfig *FilterConfig) *WarProcessor {
+func NewWarProcessor(decompiler engine.Decompiler, workers int, filterConfig *FilterConfig, run RunContext) *WarProcessor {
 	return &WarProcessor{
-		JarProcessor: NewJarProcessor(cfrManager, workers, filterConfig),
+		JarProcessor: NewJarProcessor(decompiler, workers, filterConfig, run),
 	}
 }
 
@@ -306,16 +549,18 @@ func (p *WarProcessor) GetType() string {
 
 // DirectoryProcessor 处理目录
 type DirectoryProcessor struct {
-	cfrManager   *cfr.Manager
+	decompiler   engine.Decompiler
 	workers      int
 	filterConfig *FilterConfig
+	run          RunContext
 }
 
-func NewDirectoryProcessor(cfrManager *cfr.Manager, workers int, filterConfig *FilterConfig) *DirectoryProcessor {
+func NewDirectoryProcessor(decompiler engine.Decompiler, workers int, filterConfig *FilterConfig, run RunContext) *DirectoryProcessor {
 	return &DirectoryProcessor{
-		cfrManager:   cfrManager,
+		decompiler:   decompiler,
 		workers:      workers,
 		filterConfig: filterConfig,
+		run:          run,
 	}
 }
 
@@ -323,63 +568,141 @@ func (p *DirectoryProcessor) GetType() string {
 	return "directory"
 }
 
-func (p *DirectoryProcessor) Process(inputPath string, outputDir string, rpt *report.Report) error {
-	color.Cyan("正在处理目录: %s", inputPath)
+func (p *DirectoryProcessor) Process(ctx context.Context, inputPath string, outputDir string, rpt *report.Report) error {
+	out := rpt.Console()
+	out.Cyan(out.T("正在处理目录: %s"), inputPath)
 
-	classFiles, jarFiles, warFiles, err := ScanDirectoryComplete(inputPath, outputDir)
+	// 跳过整个输出根目录（报告所在目录），其中的 libs、resources 可能与源代码目录并列
+	classFiles, jarFiles, warFiles, err := ScanDirectoryComplete(inputPath, rpt.OutputPath)
 	if err != nil {
-		return fmt.Errorf("扫描目录失败: %v", err)
+		return fmt.Errorf(out.T("扫描目录失败: %v"), err)
 	}
 
-	color.Cyan("[SCAN] 扫描结果: %d个JAR, %d个WAR, %d个CLASS文件",
+	out.Cyan(out.T("[SCAN] 扫描结果: %d个JAR, %d个WAR, %d个CLASS文件"),
 		len(jarFiles), len(warFiles), len(classFiles))
 
+	if p.run.WebappDir != "" {
+		copiedCount, err := copyWebappDir(inputPath, p.run.WebappDir, rpt.OutputPath)
+		if err != nil {
+			out.Red(out.T("复制 web 内容失败: %s - %v"), inputPath, err)
+		} else if copiedCount > 0 {
+			out.Green(out.T("[OK] 复制了 %d 个 web 内容文件到 webapp 目录"), copiedCount)
+		}
+	}
+
 	if len(jarFiles) == 0 && len(warFiles) == 0 && len(classFiles) == 0 {
-		color.Yellow("[WARN] 未找到任何需要反编译的文件")
+		out.Warn(out.T("[WARN] 未找到任何需要反编译的文件"))
 		return nil
 	}
 
-	rpt.AddExpectedFiles(int32(len(classFiles)))
-
-	for _, jarPath := range jarFiles {
-		color.Yellow("处理JAR文件: %s", filepath.Base(jarPath))
-		jarProcessor := NewJarProcessor(p.cfrManager, p.workers, p.filterConfig)
-		if err := jarProcessor.Process(jarPath, outputDir, rpt); err != nil {
-			color.Red("处理JAR失败: %v", err)
+	// 上次运行已成功的 class 不再处理，结果已合并到报告中
+	classGroups := make([]ClassGroup, 0, len(classFiles))
+	for _, group := range GroupClasses(classFiles) {
+		group.Location = relativeLocation(group.Path, inputPath)
+		if !rpt.Completed(group.Location) {
+			classGroups = append(classGroups, group)
 		}
 	}
+	rpt.AddExpectedFiles(int32(len(classGroups)))
 
-	for _, warPath := range warFiles {
-		color.Yellow("处理WAR文件: %s", filepath.Base(warPath))
-		warProcessor := NewWarProcessor(p.cfrManager, p.workers, p.filterConfig)
-		if err := warProcessor.Process(warPath, outputDir, rpt); err != nil {
-			color.Red("处理WAR失败: %v", err)
+	for _, archivePath := range append(jarFiles, warFiles...) {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		label := relativeLocation(archivePath, inputPath)
+		if rpt.ArchiveCompleted(label) {
+			out.Cyan(out.T("[RESUME] 跳过上次已完成的 %s"), label)
+			continue
+		}
+
+		var err error
+		if strings.EqualFold(filepath.Ext(archivePath), ".war") {
+			out.Yellow(out.T("处理WAR文件: %s"), filepath.Base(archivePath))
+			err = NewWarProcessor(p.decompiler, p.workers, p.filterConfig, p.run).processFile(ctx, archivePath, label, outputDir, rpt)
+		} else {
+			out.Yellow(out.T("处理JAR文件: %s"), filepath.Base(archivePath))
+			err = NewJarProcessor(p.decompiler, p.workers, p.filterConfig, p.run).processFile(ctx, archivePath, label, outputDir, rpt)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				out.Red(out.T("处理压缩包失败: %v"), err)
+			}
+			continue
+		}
+		rpt.CompleteArchive(label)
 	}
 
-	if len(classFiles) > 0 {
-		jobs := make(chan string, len(classFiles))
-		var wg sync.WaitGroup
+	processClassGroups(ctx, p.decompiler, classGroups, inputPath, outputDir, p.workers, p.filterConfig, p.run.Cache, rpt)
 
-		for i := 0; i < p.workers; i++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				proc := NewClassProcessor(p.cfrManager)
-				for classPath := range jobs {
-					proc.Process(classPath, outputDir, rpt)
-				}
-			}()
-		}
+	return ctx.Err()
+}
 
-		for _, file := range classFiles {
-			jobs <- file
+// copyWebappDir 输入目录是解压的 web 应用（如 Tomcat webapps 下的应用目录，包含 WEB-INF）时，
+// 将其中的 web 内容复制到 webappDir，规则与 WAR 相同；不是 web 应用时不复制。跳过输出根目录
+func copyWebappDir(inputDir, webappDir, outputRoot string) (int, error) {
+	if info, err := os.Stat(filepath.Join(inputDir, "WEB-INF")); err != nil || !info.IsDir() {
+		return 0, nil
+	}
+	absOutputRoot, _ := filepath.Abs(outputRoot)
+	copiedCount := 0
+	err := filepath.Walk(inputDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
-		close(jobs)
-		wg.Wait()
+		if absPath, _ := filepath.Abs(filePath); outputRoot != "" && (absPath == absOutputRoot || strings.HasPrefix(absPath, absOutputRoot+string(os.PathSeparator))) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := relativeLocation(filePath, inputDir)
+		if strings.EqualFold(path.Ext(name), ".class") || !isWebappEntry(name) {
+			return nil
+		}
+		destPath, err := entryPath(webappDir, name)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(filePath, destPath); err != nil {
+			return err
+		}
+		copiedCount++
+		return nil
+	})
+	return copiedCount, err
+}
+
+// copyFile 复制单个文件，自动创建目标目录
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
 	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
 
-	return nil
+// relativeLocation 返回文件相对于输入目录的路径，以 / 分隔
+func relativeLocation(filePath, inputDir string) string {
+	if rel, err := filepath.Rel(inputDir, filePath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filePath)
 }
 
 // ExtractPackageName 从文件路径中提取包名
@@ -401,6 +724,24 @@ func ExtractPackageName(classPath string) string {
 	return filepath.ToSlash(filepath.Dir(classPath))
 }
 
+// classInfo 返回 class 的包路径和编译目标的 Java 版本
+// 优先使用 class 文件中记录的类名确定包路径，文件无法解析时退回到根据路径推测，版本为 0
+func classInfo(classPath string) (string, int) {
+	if cf, err := classfile.ParseFile(classPath); err == nil {
+		return cf.PackageName(), cf.JavaVersion()
+	}
+	return ExtractPackageName(classPath), 0
+}
+
+// classSourcePath 推算 class 反编译后生成的 .java 路径（相对于输出目录，以 / 分隔）
+// 优先使用 class 文件中的类名，不依赖 class 所在的目录结构
+func classSourcePath(classPath, baseDir string) string {
+	if cf, err := classfile.ParseFile(classPath); err == nil {
+		return SourcePathForClass(cf.ThisClass + ".class")
+	}
+	return SourcePathForClass(extractRelativePathFromBase(classPath, baseDir))
+}
+
 // ScanDirectoryComplete 扫描目录,返回所有class、JAR和WAR文件(包括顶层)
 func ScanDirectoryComplete(dir string, outputDir string) (classFiles []string, jarFiles []string, warFiles []string, err error) {
 	absOutputDir, _ := filepath.Abs(outputDir)
@@ -433,128 +774,3 @@ func ScanDirectoryComplete(dir string, outputDir string) (classFiles []string, j
 	})
 	return
 }
-
-// ScanDirectory 扫描目录,返回class文件、嵌套JAR文件和配置文件列表
-func ScanDirectory(dir string) (classFiles []string, jarFiles []string, resourceFiles []string, err error) {
-	resourceExts := map[string]bool{
-		".properties": true,
-		".yml":        true,
-		".yaml":       true,
-		".xml":        true,
-		".json":       true,
-		".conf":       true,
-		".config":     true,
-		".txt":        true,
-		".sql":        true,
-		".sh":         true,
-	}
-
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() {
-			ext := strings.ToLower(filepath.Ext(path))
-			switch ext {
-			case ".class":
-				classFiles = append(classFiles, path)
-			case ".jar":
-				if strings.Contains(path, "BOOT-INF/lib") || strings.Contains(path, "WEB-INF/lib") {
-					jarFiles = append(jarFiles, path)
-				}
-			default:
-				if resourceExts[ext] {
-					if strings.Contains(path, "BOOT-INF/classes") || strings.Contains(path, "WEB-INF/classes") {
-						resourceFiles = append(resourceFiles, path)
-					}
-				}
-			}
-		}
-		return nil
-	})
-	return
-}
-
-// CopyResourceFile 复制配置文件到输出目录
-func CopyResourceFile(srcPath, tempDir, outputDir string) error {
-	relPath, err := filepath.Rel(tempDir, srcPath)
-	if err != nil {
-		return err
-	}
-
-	if idx := strings.Index(relPath, "BOOT-INF/classes/"); idx != -1 {
-		relPath = relPath[idx+len("BOOT-INF/classes/"):]
-	} else if idx := strings.Index(relPath, "WEB-INF/classes/"); idx != -1 {
-		relPath = relPath[idx+len("WEB-INF/classes/"):]
-	}
-
-	destPath := filepath.Join(outputDir, "resources", relPath)
-
-	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-		return err
-	}
-
-	srcFile, err := os.Open(srcPath)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	destFile, err := os.Create(destPath)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, srcFile)
-	return err
-}
-
-// UnzipFile 解压ZIP/JAR/WAR文件
-func UnzipFile(src, dest string) error {
-	r, err := zip.OpenReader(src)
-	if err != nil {
-		return err
-	}
-	defer r.Close()
-
-	for _, f := range r.File {
-		fpath := filepath.Join(dest, f.Name)
-
-		if !strings.HasPrefix(fpath, filepath.Clean(dest)+string(os.PathSeparator)) {
-			return fmt.Errorf("非法文件路径: %s", fpath)
-		}
-
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
-				return fmt.Errorf("创建目录失败 %s: %w", fpath, err)
-			}
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
-			return err
-		}
-
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return err
-		}
-
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
-
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// blockingDecompiler 一直阻塞到 ctx 结束的测试引擎
+type blockingDecompiler struct{}
+
+func (blockingDecompiler) Name() string                { return "blocking" }
+func (blockingDecompiler) GetVersion() (string, error) { return "", nil }
+
+func (blockingDecompiler) Decompile(ctx context.Context, inputPath string, outputDir string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestClassProcessorTimeout(t *testing.T) {
+	rpt := report.New("in", t.TempDir())
+	proc := NewClassProcessor(blockingDecompiler{}, 10*time.Millisecond)
+
+	if err := proc.Process(context.Background(), "Foo.class", t.TempDir(), rpt); err == nil {
+		t.Fatal("Process() 应返回超时错误")
+	}
+	if len(rpt.Results) != 1 {
+		t.Fatalf("len(Results) = %d, want 1", len(rpt.Results))
+	}
+	if result := rpt.Results[0]; result.Success || !strings.Contains(result.Error, "超时") {
+		t.Errorf("result = %+v, want timeout failure", result)
+	}
+}
+
+func TestClassProcessorCancelled(t *testing.T) {
+	rpt := report.New("in", t.TempDir())
+	proc := NewClassProcessor(blockingDecompiler{}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := proc.Process(ctx, "Foo.class", t.TempDir(), rpt); err != context.Canceled {
+		t.Fatalf("Process() error = %v, want context.Canceled", err)
+	}
+	if len(rpt.Results) != 0 {
+		t.Errorf("中断的 class 不应计入报告, got %d results", len(rpt.Results))
+	}
+}
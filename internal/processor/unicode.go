@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
 )
 
 // unicodeEscapePattern 匹配 Unicode 转义序列 \uXXXX
@@ -29,7 +31,7 @@ func DecodeUnicodeEscapes(input string) string {
 func ProcessJavaFileUnicode(filePath string) error {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("读取文件失败: %w", err)
+		return fmt.Errorf(i18n.T("读取文件失败: %w"), err)
 	}
 
 	originalContent := string(content)
@@ -38,7 +40,7 @@ func ProcessJavaFileUnicode(filePath string) error {
 	// 只有内容发生变化时才写入
 	if decodedContent != originalContent {
 		if err := os.WriteFile(filePath, []byte(decodedContent), 0644); err != nil {
-			return fmt.Errorf("写入文件失败: %w", err)
+			return fmt.Errorf(i18n.T("写入文件失败: %w"), err)
 		}
 	}
 
@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// vscodeSettings .vscode/settings.json 中 Java 扩展使用的设置
+// 这些设置只对没有 pom.xml、build.gradle 的项目生效，有构建文件时扩展以构建文件为准
+type vscodeSettings struct {
+	SourcePaths         []string `json:"java.project.sourcePaths"`
+	ReferencedLibraries []string `json:"java.project.referencedLibraries"`
+	OutputPath          string   `json:"java.project.outputPath"`
+}
+
+// GenerateVSCodeProject 在输出目录生成 .vscode/settings.json
+// 源代码目录作为源码路径，依赖库目录中的 JAR 作为引用的库
+// 配置文件目录不包含 Java 源码，不加入源码路径
+func GenerateVSCodeProject(config *ProjectConfig) error {
+	settings := vscodeSettings{
+		SourcePaths:         []string{projectPath(config, config.SrcDir)},
+		ReferencedLibraries: []string{},
+		OutputPath:          "bin",
+	}
+	if config.LibsDir != "" {
+		settings.ReferencedLibraries = append(settings.ReferencedLibraries, projectPath(config, config.LibsDir)+"/**/*.jar")
+	}
+
+	vscodeDir := filepath.Join(config.OutputDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		return fmt.Errorf(i18n.T("创建 .vscode 目录失败: %v"), err)
+	}
+	data, err := json.MarshalIndent(settings, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(vscodeDir, "settings.json"), append(data, '\n'), 0644)
+}
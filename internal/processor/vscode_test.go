@@ -0,0 +1,41 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateVSCodeProject(t *testing.T) {
+	dir := t.TempDir()
+	config := &ProjectConfig{
+		ProjectName:  "demo",
+		OutputDir:    dir,
+		SrcDir:       filepath.Join(dir, "src"),
+		ResourcesDir: filepath.Join(dir, "resources"),
+		LibsDir:      filepath.Join(dir, "libs"),
+	}
+	os.MkdirAll(config.ResourcesDir, 0755)
+
+	if err := GenerateVSCodeProject(config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".vscode", "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"java.project.sourcePaths":         []any{"src"},
+		"java.project.referencedLibraries": []any{"libs/**/*.jar"},
+		"java.project.outputPath":          "bin",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("settings.json = %s", data)
+	}
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	EventArchive = "archive" // 开始处理一个压缩包
+	EventResult  = "result"  // 一个源文件的反编译结果
+	EventWarning = "warning" // 因资源限制跳过的内容或一般警告
+	EventSummary = "summary" // 运行结束时的汇总
+)
+
+// Event 机器可读的进度事件，每个事件输出为一行 JSON（NDJSON）
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Archive string    `json:"archive,omitempty"` // archive 事件：压缩包在输入中的路径
+	Result  *Result   `json:"result,omitempty"`
+	Warning *Warning  `json:"warning,omitempty"`
+	Summary *Summary  `json:"summary,omitempty"`
+}
+
+// Summary 运行结束时的汇总信息
+type Summary struct {
+	InputPath     string  `json:"inputPath"`
+	OutputPath    string  `json:"outputPath"`
+	ReportsDir    string  `json:"reportsDir,omitempty"` // 详细报告所在目录，保存失败时为空
+	TotalFiles    int32   `json:"totalFiles"`
+	ExpectedFiles int32   `json:"expectedFiles"`
+	SuccessCount  int32   `json:"successCount"`
+	FailureCount  int32   `json:"failureCount"`
+	CachedCount   int32   `json:"cachedCount"`
+	ResumedCount  int32   `json:"resumedCount"`
+	WarningCount  int     `json:"warningCount"`
+	Interrupted   bool    `json:"interrupted"`
+	Duration      float64 `json:"duration"` // 秒
+	// JavaVersions 各 Java 版本（class 文件的编译目标）的源文件数量
+	JavaVersions map[int]int `json:"javaVersions,omitempty"`
+}
+
+// eventWriter 串行输出事件，保证并发写入时每行完整、回调不会并发执行
+type eventWriter struct {
+	mu      sync.Mutex
+	enc     *json.Encoder // NDJSON 输出，nil 表示不输出
+	handler func(Event)   // 事件回调，nil 表示不回调
+}
+
+// SetEventOutput 以 NDJSON 格式将事件写入 w，同时不再输出进度行
+// 需要在开始处理前调用
+func (r *Report) SetEventOutput(w io.Writer) {
+	r.eventWriter().enc = json.NewEncoder(w)
+}
+
+// SetEventHandler 每个事件都调用 fn，调用是串行的，不影响进度行的输出
+// 需要在开始处理前调用
+func (r *Report) SetEventHandler(fn func(Event)) {
+	r.eventWriter().handler = fn
+}
+
+func (r *Report) eventWriter() *eventWriter {
+	if r.events == nil {
+		r.events = &eventWriter{}
+	}
+	return r.events
+}
+
+// quiet 是否以事件代替进度行
+func (r *Report) quiet() bool {
+	return r.events != nil && r.events.enc != nil
+}
+
+// emit 输出一个事件，未设置事件输出和回调时忽略
+func (r *Report) emit(event Event) {
+	if r.events == nil {
+		return
+	}
+	event.Time = time.Now()
+	r.events.mu.Lock()
+	defer r.events.mu.Unlock()
+	if r.events.enc != nil {
+		r.events.enc.Encode(event)
+	}
+	if r.events.handler != nil {
+		r.events.handler(event)
+	}
+}
+
+// StartArchive 记录开始处理一个压缩包
+func (r *Report) StartArchive(archive string) {
+	r.emit(Event{Type: EventArchive, Archive: archive})
+}
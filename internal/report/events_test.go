@@ -0,0 +1,54 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEventOutput(t *testing.T) {
+	var buf bytes.Buffer
+	rpt := New("app.jar", t.TempDir())
+	rpt.SetEventOutput(&buf)
+
+	rpt.StartArchive("app.jar")
+	rpt.SetTotalExpectedFiles(2)
+	rpt.AddResult(Result{ClassName: "A.class", Success: true})
+	rpt.AddResult(Result{ClassName: "B.class", Error: "失败"})
+	rpt.AddWarning(Warning{Archive: "app.jar", Kind: "entries"})
+	rpt.Warn("无法使用缓存")
+	if err := rpt.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	var last, message Event
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		last = Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			t.Fatalf("无效的事件 %q: %v", scanner.Text(), err)
+		}
+		types = append(types, last.Type)
+		if len(types) == 5 {
+			message = last
+		}
+	}
+
+	want := []string{EventArchive, EventResult, EventResult, EventWarning, EventWarning, EventSummary}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("events[%d] = %s, want %s", i, types[i], want[i])
+		}
+	}
+	if s := last.Summary; s == nil || s.SuccessCount != 1 || s.FailureCount != 1 || s.WarningCount != 1 || s.ReportsDir == "" {
+		t.Errorf("summary = %+v", last.Summary)
+	}
+	if w := message.Warning; w == nil || w.Kind != WarningMessage || w.Message != "无法使用缓存" || w.Archive != "" {
+		t.Errorf("message warning = %+v", message.Warning)
+	}
+}
@@ -0,0 +1,226 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// JournalFile 运行日志的文件名，与 reports 目录位于同一输出目录
+const JournalFile = ".emorad-journal.jsonl"
+
+// 运行日志的记录类型
+const (
+	recordRun     = "run"     // 第一行，记录输入路径和开始时间
+	recordResult  = "result"  // 一个源文件的反编译结果
+	recordWarning = "warning" // 因资源限制跳过的内容
+	recordArchive = "archive" // 已全部处理完成的压缩包
+)
+
+// journalRecord 运行日志中的一行
+type journalRecord struct {
+	Type      string    `json:"type"`
+	Input     string    `json:"input,omitempty"`
+	StartTime time.Time `json:"startTime,omitempty"`
+	Archive   string    `json:"archive,omitempty"`
+	Result    *Result   `json:"result,omitempty"`
+	Warning   *Warning  `json:"warning,omitempty"`
+}
+
+// journal 逐行追加的运行日志，进程意外退出时已写入的记录仍然有效
+type journal struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	done     map[string]bool // 已成功反编译的源文件
+	archives map[string]bool // 已处理完成的压缩包
+}
+
+// OpenJournal 在输出目录中创建运行日志，之后的结果和警告都会追加写入
+// resume 为 true 且存在同一输入的日志时，将其中已完成的结果合并到报告中，
+// 报告沿用上次的开始时间，生成的报告文件覆盖上次的同名文件。返回合并的结果数量
+func (r *Report) OpenJournal(resume bool) (int, error) {
+	if err := os.MkdirAll(r.OutputPath, 0755); err != nil {
+		return 0, err
+	}
+	j := &journal{
+		path:     filepath.Join(r.OutputPath, JournalFile),
+		done:     map[string]bool{},
+		archives: map[string]bool{},
+	}
+
+	var records []journalRecord
+	if resume {
+		previous, err := readJournal(j.path)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf(r.console.T("读取运行日志失败: %v"), err)
+		}
+		if len(previous) > 0 {
+			if !sameInput(previous[0].Input, r.InputPath) {
+				return 0, fmt.Errorf(r.console.T("运行日志属于其他输入 %s，请去掉 --resume 或更换输出目录"), previous[0].Input)
+			}
+			r.StartTime = previous[0].StartTime
+			records = r.mergeJournal(j, previous)
+		}
+	}
+
+	// 重写日志：丢弃需要重试的失败结果，避免日志随多次恢复不断增长
+	file, err := os.Create(j.path)
+	if err != nil {
+		return 0, fmt.Errorf(r.console.T("创建运行日志失败: %v"), err)
+	}
+	j.file = file
+	input, _ := filepath.Abs(r.InputPath)
+	j.write(journalRecord{Type: recordRun, Input: input, StartTime: r.StartTime})
+	for _, record := range records {
+		j.write(record)
+	}
+
+	r.journal = j
+	return int(r.ResumedCount), nil
+}
+
+// mergeJournal 合并上次运行的记录，返回需要保留在新日志中的记录
+// 成功的结果全部保留；失败的结果只保留已完成压缩包中的，其余在本次运行中重试
+func (r *Report) mergeJournal(j *journal, previous []journalRecord) []journalRecord {
+	for _, record := range previous {
+		if record.Type == recordArchive {
+			j.archives[record.Archive] = true
+		}
+	}
+
+	var kept []journalRecord
+	for _, record := range previous {
+		switch record.Type {
+		case recordResult:
+			result := record.Result
+			if result == nil || result.Path == "" {
+				continue
+			}
+			if !result.Success && !j.inArchive(result.Path) {
+				continue
+			}
+			if result.Success {
+				j.done[result.Path] = true
+			}
+			r.addResult(*result)
+			r.ResumedCount++
+		case recordWarning:
+			if record.Warning == nil || !j.inArchive(record.Warning.Archive) {
+				continue
+			}
+			r.Warnings = append(r.Warnings, *record.Warning)
+		case recordArchive:
+		default:
+			continue
+		}
+		kept = append(kept, record)
+	}
+	r.ExpectedFiles = r.TotalFiles
+	return kept
+}
+
+// inArchive 判断路径是否位于已完成的压缩包内
+func (j *journal) inArchive(path string) bool {
+	for archive := range j.archives {
+		if path == archive || strings.HasPrefix(path, archive+"!/") {
+			return true
+		}
+	}
+	return false
+}
+
+// write 追加一条记录，每条记录一次写入，写入失败时不影响反编译
+func (j *journal) write(record journalRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.file.Write(append(data, '\n'))
+}
+
+// readJournal 读取运行日志，忽略进程退出时未写完的最后一行
+func readJournal(path string) ([]journalRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []journalRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var record journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if len(records) == 0 && record.Type != recordRun {
+			return nil, fmt.Errorf(i18n.T("%s 不是有效的运行日志"), path)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// sameInput 比较两个输入路径是否指向同一位置
+func sameInput(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// Completed 判断源文件是否已在上次运行中成功反编译
+// path 为结果中的 Path，如 app.jar!/BOOT-INF/classes/com/acme/Foo.class
+func (r *Report) Completed(path string) bool {
+	if r.journal == nil || path == "" {
+		return false
+	}
+	r.journal.mu.Lock()
+	defer r.journal.mu.Unlock()
+	return r.journal.done[path]
+}
+
+// ArchiveCompleted 判断压缩包是否已在上次运行中全部处理完成
+func (r *Report) ArchiveCompleted(archive string) bool {
+	if r.journal == nil {
+		return false
+	}
+	r.journal.mu.Lock()
+	defer r.journal.mu.Unlock()
+	return r.journal.archives[archive]
+}
+
+// CompleteArchive 记录压缩包已全部处理完成，恢复运行时整体跳过
+func (r *Report) CompleteArchive(archive string) {
+	if r.journal == nil {
+		return
+	}
+	r.journal.mu.Lock()
+	r.journal.archives[archive] = true
+	r.journal.mu.Unlock()
+	r.journal.write(journalRecord{Type: recordArchive, Archive: archive})
+}
+
+// CloseJournal 关闭运行日志；complete 为 true 表示本次运行已全部完成，删除日志
+func (r *Report) CloseJournal(complete bool) {
+	if r.journal == nil {
+		return
+	}
+	r.journal.mu.Lock()
+	r.journal.file.Close()
+	r.journal.mu.Unlock()
+	if complete {
+		os.Remove(r.journal.path)
+	}
+	r.journal = nil
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalResume(t *testing.T) {
+	outputDir := t.TempDir()
+	inputDir := t.TempDir()
+
+	first := New(inputDir, outputDir)
+	if _, err := first.OpenJournal(false); err != nil {
+		t.Fatal(err)
+	}
+	first.AddResult(Result{ClassName: "A.class", Path: "A.class", Success: true})
+	first.AddResult(Result{ClassName: "B.class", Path: "B.class", Error: "失败"})
+	first.AddResult(Result{ClassName: "C.class", Path: "lib/c.jar!/C.class", Error: "失败"})
+	first.AddWarning(Warning{Archive: "lib/c.jar", Entry: "big.bin", Kind: "ratio"})
+	first.CompleteArchive("lib/c.jar")
+	first.AddResult(Result{ClassName: "D.class", Path: "lib/d.jar!/D.class", Success: true})
+	first.CloseJournal(false)
+
+	second := New(inputDir, outputDir)
+	resumed, err := second.OpenJournal(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// B 失败且不在已完成的压缩包中，需要重试
+	if resumed != 3 || len(second.Results) != 3 || second.FailureCount != 1 {
+		t.Errorf("resumed = %d, results = %d, failures = %d", resumed, len(second.Results), second.FailureCount)
+	}
+	if !second.StartTime.Equal(first.StartTime) {
+		t.Errorf("StartTime = %v, want %v", second.StartTime, first.StartTime)
+	}
+	if len(second.Warnings) != 1 {
+		t.Errorf("warnings = %d, want 1", len(second.Warnings))
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"成功的 class", second.Completed("A.class"), true},
+		{"失败的 class", second.Completed("B.class"), false},
+		{"已完成压缩包中的 class", second.Completed("lib/d.jar!/D.class"), true},
+		{"已完成的压缩包", second.ArchiveCompleted("lib/c.jar"), true},
+		{"未完成的压缩包", second.ArchiveCompleted("lib/d.jar"), false},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// 重写后的日志再次恢复，结果不重复
+	second.CloseJournal(false)
+	third := New(inputDir, outputDir)
+	if resumed, err := third.OpenJournal(true); err != nil || resumed != 3 {
+		t.Errorf("再次恢复 resumed = %d, err = %v", resumed, err)
+	}
+	third.CloseJournal(true)
+	if _, err := os.Stat(filepath.Join(outputDir, JournalFile)); !os.IsNotExist(err) {
+		t.Errorf("完成后运行日志未删除: %v", err)
+	}
+}
+
+func TestJournalOtherInput(t *testing.T) {
+	outputDir := t.TempDir()
+
+	first := New(t.TempDir(), outputDir)
+	if _, err := first.OpenJournal(false); err != nil {
+		t.Fatal(err)
+	}
+	first.CloseJournal(false)
+
+	if _, err := New(t.TempDir(), outputDir).OpenJournal(true); err == nil {
+		t.Error("其他输入的运行日志应返回错误")
+	}
+}
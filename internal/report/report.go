@@ -5,39 +5,66 @@ import (
 	"fmt"
 	"html"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/i18n"
 )
 
 const consoleWidth = 80
 
 // Result 表示单个文件的反编译结果
 type Result struct {
-	ClassName   string    `json:"className"`
-	PackageName string    `json:"packageName"`
-	Success     bool      `json:"success"`
-	Error       string    `json:"error,omitempty"`
-	TimeTaken   float64   `json:"timeTaken"`
-	TimeStamp   time.Time `json:"timestamp"`
+	ClassName    string    `json:"className"`
+	PackageName  string    `json:"packageName"`
+	Path         string    `json:"path,omitempty"` // 在输入中的位置，如 app.jar!/BOOT-INF/classes/com/acme/Foo.class
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+	Engine       string    `json:"engine,omitempty"`       // 生成最终源码的反编译引擎
+	InnerClasses int       `json:"innerClasses,omitempty"` // 随该类一起反编译的内部类、匿名类数量
+	Cached       bool      `json:"cached,omitempty"`       // 是否直接使用了缓存的结果
+	JavaVersion  int       `json:"javaVersion,omitempty"`  // class 文件的编译目标，如 8、17；1 表示 1.4 及更早
+	TimeTaken    float64   `json:"timeTaken"`
+	TimeStamp    time.Time `json:"timestamp"`
 }
 
+// Warning 表示处理过程中因资源限制被跳过的内容，或 Kind 为 WarningMessage 的一般警告
+type Warning struct {
+	Archive string `json:"archive,omitempty"` // 所在的压缩包
+	Entry   string `json:"entry,omitempty"`   // 压缩包中的条目，为空表示整个压缩包
+	Kind    string `json:"kind"`              // 限制类型：entries、size、ratio、depth，一般警告为 message
+	Message string `json:"message"`
+}
+
+// WarningMessage 一般警告（控制台的 [WARN] 消息）的类型，只输出为事件，不计入资源限制
+const WarningMessage = "message"
+
 // Report 表示整体反编译报告
 type Report struct {
-	InputPath     string     `json:"inputPath"`
-	OutputPath    string     `json:"outputPath"`
-	StartTime     time.Time  `json:"startTime"`
-	EndTime       time.Time  `json:"endTime"`
-	TotalFiles    int32      `json:"totalFiles"`    // 已处理的文件数
-	ExpectedFiles int32      `json:"expectedFiles"` // 预期要处理的总文件数
-	SuccessCount  int32      `json:"successCount"`
-	FailureCount  int32      `json:"failureCount"`
-	Results       []Result   `json:"results"`
-	mu            sync.Mutex // 保护Results切片
+	InputPath     string       `json:"inputPath"`
+	OutputPath    string       `json:"outputPath"`
+	StartTime     time.Time    `json:"startTime"`
+	EndTime       time.Time    `json:"endTime"`
+	TotalFiles    int32        `json:"totalFiles"`    // 已处理的文件数
+	ExpectedFiles int32        `json:"expectedFiles"` // 预期要处理的总文件数
+	SuccessCount  int32        `json:"successCount"`
+	FailureCount  int32        `json:"failureCount"`
+	CachedCount   int32        `json:"cachedCount,omitempty"`  // 成功数量中使用缓存的数量
+	ResumedCount  int32        `json:"resumedCount,omitempty"` // 从上次中断的运行中合并的结果数量
+	Interrupted   bool         `json:"interrupted,omitempty"`  // 是否因超时或中断提前结束
+	Results       []Result     `json:"results"`
+	Warnings      []Warning    `json:"warnings,omitempty"`     // 触发资源限制而跳过的内容
+	JavaVersions  map[int]int  `json:"javaVersions,omitempty"` // 各 Java 版本（class 文件编译目标）的源文件数量
+	mu            sync.Mutex   // 保护Results和Warnings切片
+	journal       *journal     // 运行日志，nil 表示不记录
+	events        *eventWriter // 事件输出和回调，未设置 NDJSON 输出时显示进度行
+	console       *console.Console
 }
 
 // New 创建新的反编译报告
@@ -50,10 +77,50 @@ func New(inputPath, outputPath string) *Report {
 	}
 }
 
+// SetConsole 设置进度行、汇总和 HTML 报告使用的控制台及语言，未设置时写入 color.Output
+// 需要在开始处理前调用
+func (r *Report) SetConsole(c *console.Console) {
+	r.console = c
+}
+
+// Console 返回报告使用的控制台，处理器通过它输出消息
+func (r *Report) Console() *console.Console {
+	return r.console
+}
+
 // AddResult 添加单个反编译结果并更新进度
 func (r *Report) AddResult(result Result) {
+	completed := r.addResult(result)
+	if r.journal != nil && result.Path != "" {
+		if result.Success {
+			r.journal.mu.Lock()
+			r.journal.done[result.Path] = true
+			r.journal.mu.Unlock()
+		}
+		r.journal.write(journalRecord{Type: recordResult, Result: &result})
+	}
+
+	r.emit(Event{Type: EventResult, Result: &result})
+	if r.quiet() {
+		return
+	}
+
+	expected := atomic.LoadInt32(&r.ExpectedFiles)
+
+	// 计算并显示进度
+	if expected > 0 {
+		progress := float64(completed) / float64(expected) * 100
+		fmt.Fprintf(r.console.Writer(), r.console.T("\r反编译进度: %.1f%% (%d/%d)"), progress, completed, expected)
+	}
+}
+
+// addResult 更新计数并保存结果，返回已处理的文件数
+func (r *Report) addResult(result Result) int32 {
 	if result.Success {
 		atomic.AddInt32(&r.SuccessCount, 1)
+		if result.Cached {
+			atomic.AddInt32(&r.CachedCount, 1)
+		}
 	} else {
 		atomic.AddInt32(&r.FailureCount, 1)
 	}
@@ -63,14 +130,29 @@ func (r *Report) AddResult(result Result) {
 	r.Results = append(r.Results, result)
 	r.mu.Unlock()
 
-	completed := atomic.AddInt32(&r.TotalFiles, 1)
-	expected := atomic.LoadInt32(&r.ExpectedFiles)
+	return atomic.AddInt32(&r.TotalFiles, 1)
+}
 
-	// 计算并显示进度
-	if expected > 0 {
-		progress := float64(completed) / float64(expected) * 100
-		fmt.Printf("\r反编译进度: %.1f%% (%d/%d)", progress, completed, expected)
+// AddWarning 记录因资源限制被跳过的内容
+func (r *Report) AddWarning(warning Warning) {
+	r.mu.Lock()
+	r.Warnings = append(r.Warnings, warning)
+	r.mu.Unlock()
+	if r.journal != nil {
+		r.journal.write(journalRecord{Type: recordWarning, Warning: &warning})
+	}
+	r.emit(Event{Type: EventWarning, Warning: &warning})
+
+	target := warning.Archive
+	if warning.Entry != "" {
+		target += "!/" + warning.Entry
 	}
+	r.console.Red(r.console.T("\n[LIMIT] 已跳过 %s: %s"), target, warning.Message)
+}
+
+// Warn 将一般警告输出为 warning 事件，消息已由控制台输出，不写入报告和运行日志
+func (r *Report) Warn(message string) {
+	r.emit(Event{Type: EventWarning, Warning: &Warning{Kind: WarningMessage, Message: message}})
 }
 
 // GetTotalExpectedFiles 获取预期总文件数
@@ -97,11 +179,13 @@ func (r *Report) Generate() error {
 	totalFiles := atomic.LoadInt32(&r.TotalFiles)
 
 	// 清除进度显示的行
-	fmt.Print("\r" + strings.Repeat(" ", consoleWidth) + "\r")
+	if !r.quiet() {
+		fmt.Fprint(r.console.Writer(), "\r"+strings.Repeat(" ", consoleWidth)+"\r")
+	}
 
 	// 打印摘要报告
-	color.Green("\n[OK] 反编译完成！")
-	fmt.Printf(`
+	r.console.Green(r.console.T("\n[OK] 反编译完成！"))
+	fmt.Fprintf(r.console.Writer(), r.console.T(`
 ============================================
               反编译报告摘要
 ============================================
@@ -117,7 +201,7 @@ func (r *Report) Generate() error {
    - 成功率: %.2f%%
 
 ============================================
-`,
+`),
 		r.InputPath,
 		r.OutputPath,
 		duration.Seconds(),
@@ -126,13 +210,44 @@ func (r *Report) Generate() error {
 		failureCount,
 		getSuccessRate(successCount, totalFiles))
 
+	if cached := atomic.LoadInt32(&r.CachedCount); cached > 0 {
+		r.console.Cyan(r.console.T("[CACHE] 其中 %d 个来自缓存，%d 个重新反编译"), cached, successCount-cached)
+	}
+	if resumed := atomic.LoadInt32(&r.ResumedCount); resumed > 0 {
+		r.console.Cyan(r.console.T("[RESUME] 其中 %d 个结果来自上次中断的运行"), resumed)
+	}
+	if len(r.Warnings) > 0 {
+		r.console.Red(r.console.T("[LIMIT] %d 项内容因资源限制被跳过，详见报告"), len(r.Warnings))
+	}
+	r.JavaVersions = r.JavaVersionHistogram()
+	if len(r.JavaVersions) > 0 {
+		r.console.Cyan(r.console.T("[JDK] class 版本分布: %s"), formatJavaVersions(r.JavaVersions))
+	}
+
+	summary := &Summary{
+		InputPath:     r.InputPath,
+		OutputPath:    r.OutputPath,
+		TotalFiles:    totalFiles,
+		ExpectedFiles: atomic.LoadInt32(&r.ExpectedFiles),
+		SuccessCount:  successCount,
+		FailureCount:  failureCount,
+		CachedCount:   atomic.LoadInt32(&r.CachedCount),
+		ResumedCount:  atomic.LoadInt32(&r.ResumedCount),
+		WarningCount:  len(r.Warnings),
+		Interrupted:   r.Interrupted,
+		Duration:      duration.Seconds(),
+		JavaVersions:  r.JavaVersions,
+	}
+
 	// 生成详细报告文件
 	if err := r.saveDetailedReports(); err != nil {
-		color.Yellow("[WARN] 保存详细报告失败: %v", err)
+		r.console.Warn(r.console.T("[WARN] 保存详细报告失败: %v"), err)
 	} else {
-		color.Cyan("[INFO] 详细报告已保存到: %s/reports/", r.OutputPath)
+		summary.ReportsDir = filepath.Join(r.OutputPath, "reports")
+		r.console.Cyan(r.console.T("[INFO] 详细报告已保存到: %s/reports/"), r.OutputPath)
 	}
 
+	r.emit(Event{Type: EventSummary, Summary: summary})
 	return nil
 }
 
@@ -175,13 +290,16 @@ func (r *Report) saveHTMLReport(path string) error {
 	failureCount := atomic.LoadInt32(&r.FailureCount)
 	totalFiles := atomic.LoadInt32(&r.TotalFiles)
 	duration := r.EndTime.Sub(r.StartTime)
+	label := func(msg string) string {
+		return html.EscapeString(r.console.T(msg))
+	}
 
 	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="zh-CN">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>反编译报告 - %s</title>
+    <title>%s - %s</title>
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif; background: #f5f5f5; padding: 20px; }
@@ -207,6 +325,8 @@ func (r *Report) saveHTMLReport(path string) error {
         .status { display: inline-block; padding: 4px 12px; border-radius: 12px; font-size: 12px; font-weight: 600; }
         .status.success { background: #d4edda; color: #155724; }
         .status.failure { background: #f8d7da; color: #721c24; }
+        .status.cached { background: #d1ecf1; color: #0c5460; }
+        .bar { height: 12px; background: #667eea; border-radius: 6px; min-width: 2px; }
         .error-msg { color: #dc3545; font-size: 12px; max-width: 300px; overflow: hidden; text-overflow: ellipsis; }
         .footer { padding: 20px 30px; border-top: 1px solid #dee2e6; color: #6c757d; font-size: 14px; text-align: center; }
     </style>
@@ -214,64 +334,80 @@ func (r *Report) saveHTMLReport(path string) error {
 <body>
     <div class="container">
         <div class="header">
-            <h1>🎯 反编译报告</h1>
-            <div class="subtitle">生成时间: %s</div>
+            <h1>🎯 %s</h1>
+            <div class="subtitle">%s: %s</div>
         </div>
 
         <div class="summary">
             <div class="stat-card">
-                <div class="label">总文件数</div>
+                <div class="label">%s</div>
                 <div class="value">%d</div>
             </div>
             <div class="stat-card success">
-                <div class="label">成功</div>
+                <div class="label">%s</div>
                 <div class="value">%d</div>
             </div>
             <div class="stat-card failure">
-                <div class="label">失败</div>
+                <div class="label">%s</div>
                 <div class="value">%d</div>
             </div>
             <div class="stat-card">
-                <div class="label">成功率</div>
+                <div class="label">%s</div>
                 <div class="value">%.1f%%</div>
             </div>
             <div class="stat-card">
-                <div class="label">耗时</div>
+                <div class="label">%s</div>
                 <div class="value">%.1fs</div>
             </div>
         </div>
 
         <div class="details">
-            <h2>📋 处理详情</h2>
+            <h2>📋 %s</h2>
             <div class="table-wrapper">
                 <table>
                     <thead>
                         <tr>
-                            <th>文件名</th>
-                            <th>包名</th>
-                            <th>状态</th>
-                            <th>耗时(秒)</th>
-                            <th>错误信息</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
                         </tr>
                     </thead>
                     <tbody>`,
-		r.StartTime.Format("2006-01-02 15:04:05"),
-		r.StartTime.Format("2006-01-02 15:04:05"),
-		totalFiles,
-		successCount,
-		failureCount,
-		getSuccessRate(successCount, totalFiles),
-		duration.Seconds())
+		i18n.HTMLLangOf(r.console.Lang()),
+		label("反编译报告"), r.StartTime.Format("2006-01-02 15:04:05"),
+		label("反编译报告"),
+		label("生成时间"), r.StartTime.Format("2006-01-02 15:04:05"),
+		label("总文件数"), totalFiles,
+		label("成功"), successCount,
+		label("失败"), failureCount,
+		label("成功率"), getSuccessRate(successCount, totalFiles),
+		label("耗时"), duration.Seconds(),
+		label("处理详情"),
+		label("文件名"), label("包名"), label("状态"), label("引擎"), label("耗时(秒)"), label("错误信息"))
 
 	// 添加每个结果的行
 	for _, result := range r.Results {
 		status := "success"
-		statusText := "成功"
+		statusText := r.console.T("成功")
 		errorMsg := "-"
+		engineName := result.Engine
+		if engineName == "" {
+			engineName = "-"
+		}
+		className := html.EscapeString(result.ClassName)
+		if result.InnerClasses > 0 {
+			className += fmt.Sprintf(r.console.T(" <small>(+%d 内部类)</small>"), result.InnerClasses)
+		}
 		if !result.Success {
 			status = "failure"
-			statusText = "失败"
+			statusText = r.console.T("失败")
 			errorMsg = html.EscapeString(result.Error)
+		} else if result.Cached {
+			status = "cached"
+			statusText = r.console.T("缓存")
 		}
 
 		htmlContent += fmt.Sprintf(`
@@ -279,35 +415,200 @@ func (r *Report) saveHTMLReport(path string) error {
                             <td>%s</td>
                             <td>%s</td>
                             <td><span class="status %s">%s</span></td>
+                            <td>%s</td>
                             <td>%.3f</td>
                             <td><div class="error-msg">%s</div></td>
                         </tr>`,
-			html.EscapeString(result.ClassName),
+			className,
 			html.EscapeString(result.PackageName),
 			status,
 			statusText,
+			html.EscapeString(engineName),
 			result.TimeTaken,
 			errorMsg)
 	}
 
-	htmlContent += fmt.Sprintf(`
+	htmlContent += `
                     </tbody>
                 </table>
             </div>
         </div>
+`
 
+	// Java 版本分布
+	if len(r.JavaVersions) > 0 {
+		htmlContent += fmt.Sprintf(`
+        <div class="details">
+            <h2>☕ %s</h2>
+            <div class="table-wrapper">
+                <table>
+                    <thead>
+                        <tr>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th style="width: 60%%"></th>
+                        </tr>
+                    </thead>
+                    <tbody>`,
+			label("Java 版本分布"), label("Java 版本"), label("源文件数"))
+		maxCount := 0
+		for _, count := range r.JavaVersions {
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+		for _, version := range sortedJavaVersions(r.JavaVersions) {
+			count := r.JavaVersions[version]
+			htmlContent += fmt.Sprintf(`
+                        <tr>
+                            <td>%s</td>
+                            <td>%d</td>
+                            <td><div class="bar" style="width: %.1f%%"></div></td>
+                        </tr>`,
+				html.EscapeString(javaVersionName(version)), count, float64(count)/float64(maxCount)*100)
+		}
+		htmlContent += `
+                    </tbody>
+                </table>
+            </div>
+        </div>
+`
+	}
+
+	// 资源限制
+	if len(r.Warnings) > 0 {
+		htmlContent += fmt.Sprintf(`
+        <div class="details">
+            <h2>⚠️ %s</h2>
+            <div class="table-wrapper">
+                <table>
+                    <thead>
+                        <tr>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                            <th>%s</th>
+                        </tr>
+                    </thead>
+                    <tbody>`,
+			label("资源限制"), label("压缩包"), label("条目"), label("类型"), label("说明"))
+		for _, warning := range r.Warnings {
+			entry := warning.Entry
+			if entry == "" {
+				entry = "-"
+			}
+			htmlContent += fmt.Sprintf(`
+                        <tr>
+                            <td>%s</td>
+                            <td>%s</td>
+                            <td>%s</td>
+                            <td><div class="error-msg">%s</div></td>
+                        </tr>`,
+				html.EscapeString(warning.Archive),
+				html.EscapeString(entry),
+				html.EscapeString(warning.Kind),
+				html.EscapeString(warning.Message))
+		}
+		htmlContent += `
+                    </tbody>
+                </table>
+            </div>
+        </div>
+`
+	}
+
+	htmlContent += fmt.Sprintf(`
         <div class="footer">
-            <p>📂 输入: %s</p>
-            <p>📁 输出: %s</p>
+            <p>📂 %s: %s</p>
+            <p>📁 %s: %s</p>
             <p>Powered by Emorad - Explore More Of Reverse And Decompile</p>
         </div>
     </div>
 </body>
-</html>`, r.InputPath, r.OutputPath)
+</html>`, label("输入"), r.InputPath, label("输出"), r.OutputPath)
 
 	return os.WriteFile(path, []byte(htmlContent), 0644)
 }
 
+// JavaVersionHistogram 按 class 文件的编译目标统计源文件数量，无法识别版本的结果不计入
+func (r *Report) JavaVersionHistogram() map[int]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	histogram := map[int]int{}
+	for _, result := range r.Results {
+		if result.JavaVersion > 0 {
+			histogram[result.JavaVersion]++
+		}
+	}
+	return histogram
+}
+
+// ApplicationJavaVersionHistogram 与 JavaVersionHistogram 相同，但只统计应用自身的 class，用于确定项目的 Java 版本
+// 不计入嵌套 JAR 和目录中 lib 目录下 JAR 里的依赖库、module-info 以及 META-INF/versions 下的多版本 class
+func (r *Report) ApplicationJavaVersionHistogram() map[int]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	histogram := map[int]int{}
+	for _, result := range r.Results {
+		if result.JavaVersion > 0 && isApplicationClass(result) {
+			histogram[result.JavaVersion]++
+		}
+	}
+	return histogram
+}
+
+// isApplicationClass 根据结果在输入中的位置判断是否为应用自身的 class
+func isApplicationClass(result Result) bool {
+	if result.ClassName == "module-info.class" {
+		return false
+	}
+	parts := strings.Split(result.Path, "!/")
+	if len(parts) > 2 {
+		// app.jar!/BOOT-INF/lib/dep.jar!/com/acme/Foo.class
+		return false
+	}
+	entry := parts[len(parts)-1]
+	if strings.HasPrefix(entry, "META-INF/versions/") || strings.Contains(entry, "/META-INF/versions/") {
+		return false
+	}
+	if len(parts) == 2 {
+		// 目录输入中 lib 目录下的 JAR，如 WEB-INF/lib/dep.jar!/com/acme/Foo.class
+		for _, dir := range strings.Split(path.Dir(parts[0]), "/") {
+			if dir == "lib" || dir == "libs" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortedJavaVersions 返回从低到高排列的 Java 版本
+func sortedJavaVersions(histogram map[int]int) []int {
+	versions := make([]int, 0, len(histogram))
+	for version := range histogram {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// formatJavaVersions 格式化版本分布，如 Java 8: 120, Java 11: 3
+func formatJavaVersions(histogram map[int]int) string {
+	parts := make([]string, 0, len(histogram))
+	for _, version := range sortedJavaVersions(histogram) {
+		parts = append(parts, fmt.Sprintf("%s: %d", javaVersionName(version), histogram[version]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// javaVersionName 返回 Java 版本的显示名称
+func javaVersionName(version int) string {
+	if version == 1 {
+		return "Java ≤1.4"
+	}
+	return fmt.Sprintf("Java %d", version)
+}
+
 // getSuccessRate 计算成功率
 func getSuccessRate(success, total int32) float64 {
 	if total == 0 {
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/jiaozhu/emorad/internal/console"
+)
+
+func TestApplicationJavaVersionHistogram(t *testing.T) {
+	r := New("app.jar", t.TempDir())
+	r.SetConsole(console.New(io.Discard, false, ""))
+	for _, result := range []Result{
+		{ClassName: "A.class", Path: "app.jar!/BOOT-INF/classes/com/acme/A.class", JavaVersion: 8},
+		{ClassName: "B.class", Path: "app.jar!/BOOT-INF/classes/com/acme/B.class", JavaVersion: 8},
+		{ClassName: "C.class", Path: "app.jar!/BOOT-INF/classes/com/acme/C.class", JavaVersion: 11},
+		{ClassName: "module-info.class", Path: "app.jar!/module-info.class", JavaVersion: 9},
+		{ClassName: "D.class", Path: "app.jar!/META-INF/versions/17/com/acme/D.class", JavaVersion: 17},
+		{ClassName: "Dep.class", Path: "app.jar!/BOOT-INF/lib/dep.jar!/org/dep/Dep.class", JavaVersion: 21},
+		{ClassName: "Lib.class", Path: "WEB-INF/lib/lib.jar!/org/lib/Lib.class", JavaVersion: 21},
+		{ClassName: "E.class", Path: "WEB-INF/classes/com/acme/E.class", JavaVersion: 8},
+		{ClassName: "F.class", Path: "module.jar!/com/acme/F.class", JavaVersion: 11},
+	} {
+		r.AddResult(result)
+	}
+
+	want := map[int]int{8: 3, 11: 2}
+	if got := r.ApplicationJavaVersionHistogram(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplicationJavaVersionHistogram() = %v, want %v", got, want)
+	}
+	if got := r.JavaVersionHistogram(); got[21] != 2 || got[17] != 1 {
+		t.Errorf("JavaVersionHistogram() = %v, want all classes", got)
+	}
+}
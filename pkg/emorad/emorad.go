@@ -0,0 +1,278 @@
+// Package emorad 提供在 Go 程序中调用 emorad 反编译 JAR、WAR、class 文件和目录的接口
+//
+// 与命令行不同，Decompile 默认不向标准输出打印任何内容：进度通过 Options 中的回调获取，
+// 需要命令行同样的彩色控制台输出时设置 Options.Log。
+//
+//	rpt, err := emorad.Decompile(ctx, emorad.Options{
+//		Input:    "app.jar",
+//		Output:   "out",
+//		OnResult: func(r emorad.Result) { log.Println(r.Path, r.Success) },
+//	})
+package emorad
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/jiaozhu/emorad/internal/console"
+	"github.com/jiaozhu/emorad/internal/decompile"
+	"github.com/jiaozhu/emorad/internal/i18n"
+	"github.com/jiaozhu/emorad/internal/processor"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+// 退出码，与命令行相同，见 ExitCode
+const (
+	ExitOK      = decompile.ExitOK      // 全部成功，或失败率未超过阈值
+	ExitGeneric = decompile.ExitGeneric // 其他错误
+	ExitInput   = decompile.ExitInput   // 输入错误：路径不存在、文件类型不支持、过滤规则无效
+	ExitSetup   = decompile.ExitSetup   // 环境错误：缺少 Java、无法获取反编译器、无法创建输出目录
+	ExitPartial = decompile.ExitPartial // 部分失败：失败率超过 MaxErrorRate，或运行被中断
+	ExitTotal   = decompile.ExitTotal   // 全部失败：没有任何源文件反编译成功
+)
+
+// DefaultExcludes 默认排除的框架包前缀，Options.Excludes 为 nil 时使用
+var DefaultExcludes = append([]string(nil), processor.DefaultExcludes...)
+
+// Options 反编译选项，零值表示与命令行默认值相同的行为
+type Options struct {
+	Input  string // 输入的 JAR、WAR、class 文件或目录；设置 FS 时为 FS 中的路径
+	FS     fs.FS  // 非空时从 FS 读取输入，输入会先复制到临时目录
+	Output string // 输出目录，为空时使用 ./output
+
+	Workers         int               // 并发数，0 表示 CPU 核数
+	Includes        []string          // 包含规则，非空时只处理匹配的 class
+	Excludes        []string          // 排除规则，nil 时使用 DefaultExcludes，空切片表示不排除
+	IncludeLibs     bool              // 是否处理 lib 目录下的依赖 JAR
+	JarIncludes     []string          // 只处理名称包含这些关键字的依赖 JAR
+	CopyResources   bool              // 是否复制配置文件到输出目录
+	CopyLibJars     bool              // 是否复制依赖 JAR 到 libs 目录
+	GenerateIDEA    bool              // 是否生成 IDEA 项目配置
+	GenerateMaven   bool              // 是否生成 Maven pom.xml，同时复制依赖 JAR
+	GenerateGradle  string            // 生成 Gradle 构建文件使用的 DSL：groovy、kotlin，为空时不生成
+	GenerateEclipse bool              // 是否生成 Eclipse 项目配置，同时复制依赖 JAR
+	GenerateVSCode  bool              // 是否生成 VS Code 项目配置，同时复制依赖 JAR
+	Layout          string            // 输出目录结构：flat、maven，为空时为 flat
+	Engine          string            // 反编译引擎：cfr、procyon、vineflower，为空时使用 CFR
+	FallbackEngine  string            // 主引擎失败时使用的备用引擎
+	CFRJar          string            // 指定 CFR JAR 路径，为空时自动查找或下载
+	CFROptions      map[string]string // 传给 CFR 的选项
+	ClassTimeout    time.Duration     // 单个 class 的反编译超时，0 表示不限制
+	BatchSize       int               // 每个 CFR 进程处理的 class 数量，0 表示逐个处理
+	Daemon          bool              // 是否使用常驻反编译进程
+	CacheDir        string            // 反编译结果缓存目录，为空时不使用缓存
+	Mirrors         []string          // 反编译器下载镜像
+	Limits          *ArchiveLimits    // 压缩包资源限制，nil 时使用默认限制
+	Resume          bool              // 是否跳过上次中断的运行中已完成的内容，不能与 FS 同时使用
+	MaxErrorRate    float64           // 允许的最大失败率（0-1），超过时返回 ExitPartial 错误
+
+	OnArchive func(archive string) // 开始处理一个压缩包时调用
+	OnResult  func(Result)         // 每个源文件反编译完成时调用
+	OnWarning func(Warning)        // 内容因资源限制被跳过或出现一般警告时调用
+
+	Log   io.Writer // 非空时写入与命令行相同的控制台输出，为空时不输出
+	Color bool      // Log 中是否包含 ANSI 颜色
+	// Lang 控制台输出和 HTML 报告的语言：zh、en，为空时为中文
+	// 返回的错误和报告中的失败原因使用进程的默认语言
+	Lang string
+}
+
+// ArchiveLimits 处理不可信压缩包时的资源限制，0 表示不限制
+type ArchiveLimits struct {
+	MaxTotalBytes int64   // 单个输入压缩包（含嵌套 JAR）解压和读取的总字节数
+	MaxEntries    int     // 单个压缩包的条目数
+	MaxRatio      float64 // 单个条目的压缩比
+	MaxDepth      int     // JAR 嵌套层级，顶层压缩包为 0
+}
+
+// Result 单个源文件的反编译结果
+type Result struct {
+	ClassName    string        // 类文件名
+	PackageName  string        // 包名
+	Path         string        // 在输入中的位置，如 app.jar!/BOOT-INF/classes/com/acme/Foo.class
+	Success      bool          // 是否成功
+	Error        string        // 失败原因
+	Engine       string        // 生成最终源码的反编译引擎
+	InnerClasses int           // 随该类一起反编译的内部类数量
+	Cached       bool          // 是否直接使用了缓存的结果
+	JavaVersion  int           // class 文件的编译目标，如 8、17，无法读取时为 0
+	Duration     time.Duration // 反编译耗时
+	Time         time.Time     // 完成时间
+}
+
+// Warning 因资源限制被跳过的内容，或 Kind 为 message 的一般警告（如缓存不可用、生成项目配置失败）
+type Warning struct {
+	Archive string // 所在的压缩包，一般警告为空
+	Entry   string // 压缩包中的条目，为空表示整个压缩包
+	Kind    string // 限制类型：entries、size、ratio、depth，一般警告为 message
+	Message string
+}
+
+// Report 一次反编译的汇总结果，详细报告同时保存在输出目录的 reports 子目录
+type Report struct {
+	Input       string // Options.Input
+	Output      string // 输出目录
+	StartTime   time.Time
+	EndTime     time.Time
+	Total       int // 已处理的文件数
+	Expected    int // 预期要处理的文件数
+	Succeeded   int
+	Failed      int
+	Cached      int  // 成功数量中使用缓存的数量
+	Resumed     int  // 从上次中断的运行中合并的结果数量
+	Interrupted bool // 是否因 ctx 取消提前结束
+	// JavaVersions 各 Java 版本（class 文件的编译目标）的源文件数量
+	JavaVersions map[int]int
+	Results      []Result
+	Warnings     []Warning
+}
+
+// Decompile 反编译 opts.Input，结果写入 opts.Output
+//
+// 只要开始了处理，即使返回错误也会返回报告，如被中断或失败率超过 MaxErrorRate；
+// 错误对应的退出码见 ExitCode。多次调用可以并发执行，不会修改宿主程序的 fatih/color 设置。
+func Decompile(ctx context.Context, opts Options) (*Report, error) {
+	lang := opts.Lang
+	switch lang {
+	case "":
+		lang = i18n.Chinese
+	case i18n.Chinese, i18n.English:
+	default:
+		return nil, fmt.Errorf(i18n.T("不支持的语言 %q，可选 zh、en"), opts.Lang)
+	}
+
+	input := opts.Input
+	if opts.FS != nil {
+		if opts.Resume {
+			return nil, i18n.Error("从 fs.FS 读取输入时不支持恢复运行")
+		}
+		dir, path, err := materialize(ctx, opts.FS, opts.Input)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		input = path
+	}
+
+	outputDir := opts.Output
+	if outputDir == "" {
+		outputDir = "output"
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// fs.FS 的输入复制到临时目录处理，报告中显示其在 FS 中的路径
+	output := decompile.Output{InputName: opts.Input}
+	if opts.Log != nil {
+		output.Console = console.New(opts.Log, opts.Color, lang)
+	} else {
+		output.Console = console.New(io.Discard, false, lang)
+	}
+	if opts.OnArchive != nil || opts.OnResult != nil || opts.OnWarning != nil {
+		output.OnEvent = opts.dispatch
+	}
+	rpt, err := decompile.Run(ctx, input, outputDir, workers, opts.filterConfig(), output)
+	if rpt == nil {
+		return nil, err
+	}
+	return newReport(rpt), err
+}
+
+// ExitCode 返回错误对应的退出码，nil 为 ExitOK
+func ExitCode(err error) int {
+	return decompile.ExitCode(err)
+}
+
+// filterConfig 将选项转换为内部的过滤配置
+func (opts Options) filterConfig() *processor.FilterConfig {
+	config := processor.NewDefaultFilterConfig()
+	config.Includes = opts.Includes
+	if opts.Excludes != nil {
+		config.Excludes = opts.Excludes
+	}
+	config.SkipLibs = !opts.IncludeLibs
+	config.JarIncludes = opts.JarIncludes
+	config.CopyResources = opts.CopyResources
+	config.CopyLibJars = opts.CopyLibJars
+	config.GenerateIDEA = opts.GenerateIDEA
+	config.GenerateMaven = opts.GenerateMaven
+	config.GenerateGradle = opts.GenerateGradle
+	config.GenerateEclipse = opts.GenerateEclipse
+	config.GenerateVSCode = opts.GenerateVSCode
+	config.Layout = opts.Layout
+	config.Engine = opts.Engine
+	config.FallbackEngine = opts.FallbackEngine
+	config.CFRJar = opts.CFRJar
+	config.CFROptions = opts.CFROptions
+	config.ClassTimeout = opts.ClassTimeout
+	config.BatchSize = opts.BatchSize
+	config.UseDaemon = opts.Daemon
+	config.CacheDir = opts.CacheDir
+	config.Mirrors = opts.Mirrors
+	if opts.Limits != nil {
+		config.Limits = processor.ArchiveLimits(*opts.Limits)
+	}
+	config.Resume = opts.Resume
+	config.MaxErrorRate = opts.MaxErrorRate
+	return config
+}
+
+// dispatch 将进度事件分发给对应的回调
+func (opts Options) dispatch(event report.Event) {
+	switch {
+	case event.Type == report.EventArchive && opts.OnArchive != nil:
+		opts.OnArchive(event.Archive)
+	case event.Type == report.EventResult && opts.OnResult != nil:
+		opts.OnResult(newResult(*event.Result))
+	case event.Type == report.EventWarning && opts.OnWarning != nil:
+		opts.OnWarning(Warning(*event.Warning))
+	}
+}
+
+func newResult(r report.Result) Result {
+	return Result{
+		ClassName:    r.ClassName,
+		PackageName:  r.PackageName,
+		Path:         r.Path,
+		Success:      r.Success,
+		Error:        r.Error,
+		Engine:       r.Engine,
+		InnerClasses: r.InnerClasses,
+		Cached:       r.Cached,
+		JavaVersion:  r.JavaVersion,
+		Duration:     time.Duration(r.TimeTaken * float64(time.Second)),
+		Time:         r.TimeStamp,
+	}
+}
+
+func newReport(rpt *report.Report) *Report {
+	result := &Report{
+		Input:        rpt.InputPath,
+		Output:       rpt.OutputPath,
+		StartTime:    rpt.StartTime,
+		EndTime:      rpt.EndTime,
+		Total:        int(rpt.TotalFiles),
+		Expected:     int(rpt.ExpectedFiles),
+		Succeeded:    int(rpt.SuccessCount),
+		Failed:       int(rpt.FailureCount),
+		Cached:       int(rpt.CachedCount),
+		Resumed:      int(rpt.ResumedCount),
+		Interrupted:  rpt.Interrupted,
+		JavaVersions: rpt.JavaVersionHistogram(),
+		Results:      make([]Result, len(rpt.Results)),
+	}
+	for i, r := range rpt.Results {
+		result.Results[i] = newResult(r)
+	}
+	for _, w := range rpt.Warnings {
+		result.Warnings = append(result.Warnings, Warning(w))
+	}
+	return result
+}
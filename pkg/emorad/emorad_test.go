@@ -0,0 +1,163 @@
+package emorad
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fatih/color"
+	"github.com/jiaozhu/emorad/internal/report"
+)
+
+func TestDecompileInputError(t *testing.T) {
+	fsys := fstest.MapFS{"notes.txt": {Data: []byte("x")}}
+	missing := filepath.Join(t.TempDir(), "missing.jar")
+
+	// 宿主程序自己的 fatih/color 输出不受 Decompile 影响
+	var host bytes.Buffer
+	defer func(w io.Writer) { color.Output = w }(color.Output)
+	color.Output = &host
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"不支持的文件类型", Options{FS: fsys, Input: "notes.txt"}, "[ERROR] 不支持的文件类型"},
+		{"路径不存在", Options{Input: missing}, "[ERROR] 无法访问输入路径"},
+		{"英文输出", Options{Input: missing, Lang: "en"}, "[ERROR] Cannot access input path"},
+	}
+	var wg sync.WaitGroup
+	logs := make([]bytes.Buffer, len(tests))
+	for i, tt := range tests {
+		wg.Add(1)
+		i, tt := i, tt
+		go func() {
+			defer wg.Done()
+			tt.opts.Output = t.TempDir()
+			tt.opts.Log = &logs[i]
+			rpt, err := Decompile(context.Background(), tt.opts)
+			if rpt != nil || ExitCode(err) != ExitInput {
+				t.Errorf("%s: Decompile() = %v, %v (exit %d), want nil, exit %d", tt.name, rpt, err, ExitCode(err), ExitInput)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, tt := range tests {
+		if !strings.Contains(logs[i].String(), tt.want) {
+			t.Errorf("%s: log = %q, want %q", tt.name, logs[i].String(), tt.want)
+		}
+	}
+	color.New(color.FgGreen).Println("host")
+	if !strings.Contains(host.String(), "host") || strings.Contains(host.String(), "[ERROR]") {
+		t.Errorf("host output = %q", host.String())
+	}
+
+	if _, err := Decompile(context.Background(), Options{Input: missing, Lang: "fr"}); err == nil {
+		t.Error("Decompile(Lang: fr) succeeded, want error")
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dist/app.jar":         {Data: []byte("jar")},
+		"dist/lib/common.jar":  {Data: []byte("lib")},
+		"dist/classes/A.class": {Data: []byte("class")},
+	}
+
+	dir, path, err := materialize(context.Background(), fsys, "dist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, want := range map[string]string{"app.jar": "jar", "lib/common.jar": "lib", "classes/A.class": "class"} {
+		data, err := os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, want)
+		}
+	}
+
+	if _, _, err := materialize(context.Background(), fsys, "../dist"); err == nil {
+		t.Error("materialize(../dist) succeeded, want error")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	var archives []string
+	var results []Result
+	var warnings []Warning
+	opts := Options{
+		OnArchive: func(archive string) { archives = append(archives, archive) },
+		OnResult:  func(r Result) { results = append(results, r) },
+		OnWarning: func(w Warning) { warnings = append(warnings, w) },
+	}
+
+	rpt := report.New("app.jar", t.TempDir())
+	rpt.SetEventHandler(opts.dispatch)
+	rpt.StartArchive("app.jar")
+	rpt.AddResult(report.Result{ClassName: "A.class", Path: "app.jar!/A.class", Success: true, TimeTaken: 1.5})
+	rpt.AddWarning(report.Warning{Archive: "app.jar", Kind: "entries"})
+
+	if len(archives) != 1 || archives[0] != "app.jar" {
+		t.Errorf("archives = %v", archives)
+	}
+	if len(results) != 1 || results[0].Path != "app.jar!/A.class" || results[0].Duration.Seconds() != 1.5 {
+		t.Errorf("results = %+v", results)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != "entries" {
+		t.Errorf("warnings = %+v", warnings)
+	}
+}
+
+func TestDecompileFSInputName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 shell 脚本模拟 java")
+	}
+	bin := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "java"), []byte("#!/bin/sh\nexit 0\n"), 0755)
+	t.Setenv("PATH", bin)
+	var jar bytes.Buffer
+	zip.NewWriter(&jar).Close()
+	cfrJar := filepath.Join(bin, "cfr.jar")
+	os.WriteFile(cfrJar, jar.Bytes(), 0644)
+
+	var warnings []Warning
+	output := t.TempDir()
+	rpt, err := Decompile(context.Background(), Options{
+		FS:        fstest.MapFS{"dist/notes.txt": {Data: []byte("x")}},
+		Input:     "dist",
+		Output:    output,
+		CFRJar:    cfrJar,
+		OnWarning: func(w Warning) { warnings = append(warnings, w) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rpt.Input != "dist" {
+		t.Errorf("Input = %q, want dist", rpt.Input)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != report.WarningMessage || warnings[0].Message != "未找到任何需要反编译的文件" {
+		t.Errorf("warnings = %+v", warnings)
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(output, "reports", "report-*"))
+	if len(reports) != 2 {
+		t.Fatalf("reports = %v", reports)
+	}
+	for _, path := range reports {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "emorad-fs-") || !strings.Contains(string(data), "dist") {
+			t.Errorf("%s 中的输入路径不是 FS 中的路径", filepath.Base(path))
+		}
+	}
+}
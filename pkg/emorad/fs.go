@@ -0,0 +1,87 @@
+package emorad
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jiaozhu/emorad/internal/i18n"
+)
+
+// materialize 将 fsys 中的输入复制到临时目录，返回临时目录和输入在其中的路径
+// 反编译器以子进程方式读取文件，因此输入必须位于本地文件系统
+func materialize(ctx context.Context, fsys fs.FS, name string) (string, string, error) {
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", "", fmt.Errorf(i18n.T("无效的 fs.FS 路径: %s"), name)
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	dir, err := os.MkdirTemp("", "emorad-fs-*")
+	if err != nil {
+		return "", "", err
+	}
+	base := path.Base(name)
+	if base == "." {
+		base = "input"
+	}
+	target := filepath.Join(dir, base)
+
+	if info.IsDir() {
+		err = fs.WalkDir(fsys, name, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rel := p[len(name):]
+			if name == "." {
+				rel = p
+			}
+			dst := filepath.Join(target, filepath.FromSlash(rel))
+			if d.IsDir() {
+				return os.MkdirAll(dst, 0755)
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			return copyFile(fsys, p, dst)
+		})
+	} else {
+		err = copyFile(fsys, name, target)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return dir, target, nil
+}
+
+// copyFile 将 fsys 中的文件复制到本地路径
+func copyFile(fsys fs.FS, name, dst string) error {
+	src, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}